@@ -6,17 +6,23 @@ import (
     "log"
     "net/http"
     "os"
+    "sort"
     "strconv"
     "strings"
+    "sync"
     "time"
 
     tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
     "rss2tg/internal/config"
+    "rss2tg/internal/filter"
+    "rss2tg/internal/model"
+    "rss2tg/internal/msgtemplate"
+    "rss2tg/internal/rss"
     "rss2tg/internal/storage"
     "rss2tg/internal/stats"
 )
 
-type MessageHandler func(title, url, group string, pubDate time.Time, matchedKeywords []string) error
+type MessageHandler func(item *model.Item) error
 
 type Bot struct {
     api              *tgbotapi.BotAPI
@@ -28,10 +34,17 @@ type Bot struct {
     stats            *stats.Stats
     userState        map[int64]string
     messageHandler   MessageHandler
-    updateRSSHandler func()
+    updateRSSHandler func(cfg *config.Config)
+    healthHandler    func() []rss.FeedHealth
+    dryRunHandler    func(id, url string) ([]rss.DryRunResult, error)
+    configMu         sync.RWMutex // 保护 config、users、channels 和 replies：处理消息和热重载时持有写锁，推送文章时持有读锁
+    replies          []reply      // 处理消息时待发送的回复，释放配置锁后发送
+    topicMu          sync.Mutex // 保护分组话题的查找和创建，避免重复创建同一话题
+    templateMu       sync.Mutex // 保护消息模板缓存
+    templates        map[string]*msgtemplate.Template
 }
 
-func NewBot(token string, users []string, channels []string, db *storage.Storage, config *config.Config, configFile string, stats *stats.Stats) (*Bot, error) {
+func NewBot(token string, users []string, channels []string, db *storage.Storage, cfg *config.Config, configFile string, stats *stats.Stats) (*Bot, error) {
     api, err := tgbotapi.NewBotAPI(token)
     if err != nil {
         return nil, err
@@ -57,11 +70,11 @@ func NewBot(token string, users []string, channels []string, db *storage.Storage
         users:            userIDs,
         channels:         channels,
         db:               db,
-        config:           config,
+        config:           cfg,
         configFile:       configFile,
         stats:            stats,
         userState:        make(map[int64]string),
-        updateRSSHandler: func() {}, // 初始化为空函数
+        updateRSSHandler: func(*config.Config) {}, // 初始化为空函数
     }, nil
 }
 
@@ -69,10 +82,21 @@ func (b *Bot) SetMessageHandler(handler MessageHandler) {
     b.messageHandler = handler
 }
 
-func (b *Bot) SetUpdateRSSHandler(handler func()) {
+// SetUpdateRSSHandler 设置配置修改后更新订阅的处理器，调用时持有配置写锁
+func (b *Bot) SetUpdateRSSHandler(handler func(cfg *config.Config)) {
     b.updateRSSHandler = handler
 }
 
+// SetHealthHandler 设置获取订阅健康状态的处理器
+func (b *Bot) SetHealthHandler(handler func() []rss.FeedHealth) {
+    b.healthHandler = handler
+}
+
+// SetDryRunHandler 设置试运行关键词过滤的处理器
+func (b *Bot) SetDryRunHandler(handler func(id, url string) ([]rss.DryRunResult, error)) {
+    b.dryRunHandler = handler
+}
+
 func (b *Bot) Start() {
     log.Println("机器人已启动")
     
@@ -81,6 +105,8 @@ func (b *Bot) Start() {
         {Command: "view", Description: "查看类命令"},
         {Command: "users", Description: "用户管理命令"},
         {Command: "edit", Description: "编辑类命令"},
+        {Command: "test", Description: "试运行关键词过滤"},
+        {Command: "preview", Description: "预览消息模板"},
     //    {Command: "stats", Description: "推送统计"},
     }
     
@@ -96,84 +122,142 @@ func (b *Bot) Start() {
     updates := b.api.GetUpdatesChan(u)
 
     for update := range updates {
-        if update.CallbackQuery != nil {
-            // 处理按钮点击
-            chatID := update.CallbackQuery.Message.Chat.ID
-            userID := update.CallbackQuery.From.ID
-            
-            switch update.CallbackQuery.Data {
-            case "config":
-                b.handleConfig(chatID)
-            case "list":
-                b.handleList(chatID)
-            case "stats":
-                b.handleStats(chatID)
-            case "version":
-                b.handleVersion(chatID)
-            case "add":
-                b.handleAdd(chatID, userID)
-            case "edit":
-                b.handleEdit(chatID, userID)
-            case "delete":
-                b.handleDelete(chatID, userID)
-            case "toggle":
-                b.handleToggle(chatID, userID)
-            case "add_all":
-                b.handleAddAll(chatID, userID)
-            case "del_all":
-                b.handleDelAll(chatID, userID)
-            case "add_user":
-                b.handleAddUser(chatID, userID)
-            case "del_user":
-                b.handleDelUser(chatID, userID)
-            case "list_users":
-                b.handleListUsers(chatID)
-            }
-            
-            // 回应按钮点击
-            callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
-            if _, err := b.api.Request(callback); err != nil {
-                log.Printf("回应按钮点击失败: %v", err)
+        // 只在读写配置时持有锁，回复在释放锁后发送，避免网络请求阻塞推送文章
+        b.configMu.Lock()
+        b.handleUpdate(update)
+        replies := b.replies
+        b.replies = nil
+        b.configMu.Unlock()
+
+        b.sendReplies(replies)
+    }
+}
+
+// reply 处理消息时产生的回复
+type reply struct {
+    msg     tgbotapi.Chattable
+    onError func(err error) // 发送失败时调用，为空时只记录日志
+}
+
+// queueReply 记录待发送的回复，调用方需持有配置写锁
+func (b *Bot) queueReply(msg tgbotapi.Chattable, onError func(err error)) {
+    b.replies = append(b.replies, reply{msg: msg, onError: onError})
+}
+
+// sendReplies 按顺序发送回复，调用时不持有配置锁
+func (b *Bot) sendReplies(replies []reply) {
+    for _, r := range replies {
+        if _, err := b.api.Request(r.msg); err != nil {
+            if r.onError != nil {
+                r.onError(err)
+            } else {
+                log.Printf("发送消息失败: %v", err)
             }
-            
-            continue
         }
+    }
+}
 
-        if update.Message == nil {
-            continue
+// handleUpdate 处理一条消息或按钮点击，调用方需持有配置写锁。
+// 处理过程中不发送网络请求：回复通过 sendMessage 或 queueReply 记录，
+// 耗时的任务（如试运行、获取最新版本）在后台进行
+func (b *Bot) handleUpdate(update tgbotapi.Update) {
+    if update.CallbackQuery != nil {
+        // 处理按钮点击
+        chatID := update.CallbackQuery.Message.Chat.ID
+        userID := update.CallbackQuery.From.ID
+        
+        switch update.CallbackQuery.Data {
+        case "config":
+            b.handleConfig(chatID)
+        case "list":
+            b.handleList(chatID)
+        case "stats":
+            b.handleStats(chatID)
+        case "version":
+            b.handleVersion(chatID)
+        case "health":
+            b.handleHealth(chatID)
+        case "test":
+            b.handleTest(chatID, userID, "")
+        case "preview":
+            b.handlePreview(chatID, userID, "")
+        case "add":
+            b.handleAdd(chatID, userID)
+        case "edit":
+            b.handleEdit(chatID, userID)
+        case "delete":
+            b.handleDelete(chatID, userID)
+        case "toggle":
+            b.handleToggle(chatID, userID)
+        case "add_all":
+            b.handleAddAll(chatID, userID)
+        case "del_all":
+            b.handleDelAll(chatID, userID)
+        case "add_block":
+            b.handleAddBlock(chatID, userID)
+        case "del_block":
+            b.handleDelBlock(chatID, userID)
+        case "edit_set":
+            b.handleEditKeywordSet(chatID, userID)
+        case "link_set":
+            b.handleLinkKeywordSet(chatID, userID)
+        case "add_user":
+            b.handleAddUser(chatID, userID)
+        case "del_user":
+            b.handleDelUser(chatID, userID)
+        case "list_users":
+            b.handleListUsers(chatID)
         }
+        
+        // 回应按钮点击
+        callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
+        b.queueReply(callback, func(err error) {
+            log.Printf("回应按钮点击失败: %v", err)
+        })
+        
+        return
+    }
 
-        userID := update.Message.From.ID
-        chatID := update.Message.Chat.ID
-
-        if update.Message.IsCommand() {
-            switch update.Message.Command() {
-            case "start":
-                b.handleStart(chatID)
-            case "stats":
-                b.handleStats(chatID)
-            case "view":
-                b.handleView(chatID, userID)
-            case "edit":
-                b.handleEditCommand(chatID, userID)
-            case "config":
-                b.handleConfig(chatID)
-            case "list":
-                b.handleList(chatID)
-            case "version":
-                b.handleVersion(chatID)
-            case "add":
-                b.handleAdd(chatID, userID)
-            case "delete":
-                b.handleDelete(chatID, userID)
-            case "users":
-                b.handleUsers(chatID, userID)
-            default:
-                b.sendMessage(chatID, "未知命令，请使用 /start 查看可用命令。")
-            }
-        } else {
-            b.handleUserInput(update.Message)
+    if update.Message == nil {
+        return
+    }
+
+    userID := update.Message.From.ID
+    chatID := update.Message.Chat.ID
+
+    if update.Message.IsCommand() {
+        switch update.Message.Command() {
+        case "start":
+            b.handleStart(chatID)
+        case "stats":
+            b.handleStats(chatID)
+        case "view":
+            b.handleView(chatID, userID)
+        case "edit":
+            b.handleEditCommand(chatID, userID)
+        case "config":
+            b.handleConfig(chatID)
+        case "list":
+            b.handleList(chatID)
+        case "version":
+            b.handleVersion(chatID)
+        case "health":
+            b.handleHealth(chatID)
+        case "test":
+            b.handleTest(chatID, userID, update.Message.CommandArguments())
+        case "preview":
+            b.handlePreview(chatID, userID, strings.TrimSpace(update.Message.CommandArguments()))
+        case "add":
+            b.handleAdd(chatID, userID)
+        case "delete":
+            b.handleDelete(chatID, userID)
+        case "users":
+            b.handleUsers(chatID, userID)
+        default:
+            b.sendMessage(chatID, "未知命令，请使用 /start 查看可用命令。")
         }
+    } else {
+        b.handleUserInput(update.Message)
     }
 }
 
@@ -214,65 +298,146 @@ func formatBoldText(text string) string {
     return "*" + escapeMarkdownV2Text(text) + "*"
 }
 
-func (b *Bot) SendMessage(title, url, group string, pubDate time.Time, matchedKeywords []string) error {
+// SendMessage 推送文章，由 RSS 检查的协程调用。所需的配置在读锁下一次读取，
+// 发送过程中不再访问配置，避免与处理消息时的配置修改冲突
+func (b *Bot) SendMessage(item *model.Item) error {
+    // 订阅指定了推送目标时只发送给这些用户和频道，每个目标使用自己的消息格式
+    b.configMu.RLock()
+    users, channels := b.targetsFor(item)
+    userFormats := make([]*messageFormat, len(users))
+    for i, userID := range users {
+        userFormats[i] = b.formatFor(strconv.FormatInt(userID, 10))
+    }
+    channelFormats := make([]*messageFormat, len(channels))
+    for i, channel := range channels {
+        channelFormats[i] = b.formatFor(channel)
+    }
+    out := &outgoing{item: item, template: b.templateFor(item.SubscriptionID)}
+    sendImages := b.sendsImages(item.SubscriptionID)
+    b.configMu.RUnlock()
+
+    log.Printf("发送消息: %s", b.renderMessage(out, plainFormat))
+
+    // 开启图片发送时先下载图片，所有目标共用
+    if sendImages {
+        out.photos = fetchImages(item)
+    }
+
+    sent := 0
+    var lastErr error
+    for i, userID := range users {
+        userID := userID
+        target := strconv.FormatInt(userID, 10)
+        err := b.deliverItem(out, userFormats[i], target, 0, func(text, parseMode string) error {
+            msg := tgbotapi.NewMessage(userID, text)
+            msg.ParseMode = parseMode
+            _, err := b.api.Send(msg)
+            return err
+        })
+        if err != nil {
+            log.Printf("发送消息给用户 %d 失败: %v", userID, err)
+            lastErr = err
+        } else {
+            log.Printf("成功发送消息给用户 %d", userID)
+            b.stats.IncrementMessageCount(item.SubscriptionID)
+            sent++
+        }
+    }
+
+    for i, channel := range channels {
+        channel := channel
+        chat, threadID, err := b.channelTarget(channel, item.Group)
+        if err == nil {
+            err = b.deliverItem(out, channelFormats[i], chat, threadID, func(text, parseMode string) error {
+                return b.sendToChannel(channel, item, text, parseMode)
+            })
+        }
+        if err != nil {
+            log.Printf("发送消息到频道 %s 失败: %v", channel, err)
+            lastErr = err
+        } else {
+            log.Printf("成功发送消息到频道 %s", channel)
+            b.stats.IncrementMessageCount(item.SubscriptionID)
+            sent++
+        }
+    }
+
+    // 所有目标都发送失败时返回错误，文章不会被标记为已发送，下次检查时重试；
+    // 部分目标成功时不再重试，避免重复推送
+    if sent == 0 && lastErr != nil {
+        return fmt.Errorf("所有推送目标均发送失败: %v", lastErr)
+    }
+    return nil
+}
+
+// formatDefaultMessage 按消息格式生成内置格式的消息，未设置消息模板时使用
+func formatDefaultMessage(item *model.Item, m msgtemplate.Markup) string {
+    title, url, group, matchedKeywords := item.Title, item.Link, item.Group, item.MatchedKeywords
     chinaLoc, _ := time.LoadLocation("Asia/Shanghai")
-    pubDateChina := pubDate.In(chinaLoc)
+    pubDateChina := item.Published.In(chinaLoc)
+
+    // 加粗，空值显示为"无"
+    bold := func(text string) string {
+        if text == "" {
+            text = "无"
+        }
+        return m.Bold(text)
+    }
     
     // 处理标题（加粗）
-    formattedTitle := formatBoldText(title)
+    formattedTitle := bold(title)
     
     // 处理URL（转义所有特殊字符）
-    formattedURL := escapeMarkdownV2Text(url)
+    formattedURL := m.Escape(url)
     
     // 处理关键词（加粗并添加#）
     formattedKeywords := make([]string, len(matchedKeywords))
     for i, keyword := range matchedKeywords {
-        // 先转义关键词，再添加#和加粗
-        escapedKeyword := escapeMarkdownV2Text(keyword)
-        formattedKeywords[i] = "\\#*" + escapedKeyword + "*"
+        formattedKeywords[i] = m.Escape("#") + m.Bold(keyword)
+        if item.IsFuzzy(keyword) {
+            formattedKeywords[i] += m.Escape("(模糊)")
+        }
+    }
+    
+    // 处理评分（配置了关键词时才有意义）
+    formattedScore := ""
+    if len(matchedKeywords) > 0 {
+        formattedScore = "\n\n📈 " + m.Bold("评分:") + " " + bold(filter.FormatWeight(item.Score))
     }
     
     // 处理分组（加粗）
-    formattedGroup := formatBoldText(group)
+    formattedGroup := bold(group)
     
     // 处理时间（加粗）
     timeStr := pubDateChina.Format("2006-01-02 15:04:05")
-    formattedTime := formatBoldText(timeStr)
+    formattedTime := bold(timeStr)
     
     // 构建消息文本
-    text := fmt.Sprintf("%s\n\n🌐 *链接:* %s\n\n🔍 *关键词:* %s\n\n🏷️ *分组:* %s\n\n🕒 *时间:* %s", 
+    return fmt.Sprintf("%s\n\n🌐 %s %s\n\n🔍 %s %s%s\n\n🏷️ %s %s\n\n🕒 %s %s", 
         formattedTitle,
-        formattedURL,
-        strings.Join(formattedKeywords, " "),
-        formattedGroup,
-        formattedTime)
-    
-    log.Printf("发送消息: %s", text)
+        m.Bold("链接:"), formattedURL,
+        m.Bold("关键词:"), strings.Join(formattedKeywords, " "),
+        formattedScore,
+        m.Bold("分组:"), formattedGroup,
+        m.Bold("时间:"), formattedTime)
+}
 
-    // 发送消息
-    for _, userID := range b.users {
-        msg := tgbotapi.NewMessage(userID, text)
-        msg.ParseMode = "MarkdownV2"
-        if _, err := b.api.Send(msg); err != nil {
-            log.Printf("发送消息给用户 %d 失败: %v", userID, err)
-        } else {
-            log.Printf("成功发送消息给用户 %d", userID)
-            b.stats.IncrementMessageCount()
-        }
+// targetsFor 返回文章的 Telegram 推送目标，文章未指定时使用全局的用户和频道的副本，
+// 调用方需持有配置锁
+func (b *Bot) targetsFor(item *model.Item) ([]int64, []string) {
+    if !item.Route.HasTelegram() {
+        return append([]int64(nil), b.users...), append([]string(nil), b.channels...)
     }
-
-    for _, channel := range b.channels {
-        msg := tgbotapi.NewMessageToChannel(channel, text)
-        msg.ParseMode = "MarkdownV2"
-        if _, err := b.api.Send(msg); err != nil {
-            log.Printf("发送消息到频道 %s 失败: %v", channel, err)
-        } else {
-            log.Printf("成功发送消息到频道 %s", channel)
-            b.stats.IncrementMessageCount()
+    users := make([]int64, 0, len(item.Route.Users))
+    for _, user := range item.Route.Users {
+        userID, err := strconv.ParseInt(user, 10, 64)
+        if err != nil {
+            log.Printf("订阅 [%s] 的推送目标中用户ID无效: %s", item.SubscriptionID, user)
+            continue
         }
+        users = append(users, userID)
     }
-
-    return nil
+    return users, item.Route.Channels
 }
 
 func (b *Bot) reloadConfig() error {
@@ -296,6 +461,9 @@ func (b *Bot) handleStart(chatID int64) {
         "/config \\- 查看当前配置\n" +
         "/list \\- 列出所有RSS订阅\n" +
         "/stats \\- 查看推送统计\n" +
+        "/health \\- 查看订阅健康状态\n" +
+        "/test \\- 立即抓取订阅并试运行关键词过滤（不推送），如 /test 订阅ID、列表编号或URL\n" +
+        "/preview \\- 用示例文章预览消息模板，如 /preview 订阅ID或列表编号，/preview 0 预览全局模板\n" +
         "/version \\- 获取当前版本信息\n\n" +
         "用户管理命令（使用 /users 查看）：\n" +
         "/add\\_user \\- 添加用户\n" +
@@ -306,7 +474,11 @@ func (b *Bot) handleStart(chatID int64) {
         "/edit \\- 编辑RSS订阅\n" +
         "/delete \\- 删除RSS订阅\n" +
         "/add\\_all \\- 向所有订阅添加关键词\n" +
-        "/del\\_all \\- 从所有订阅删除关键词"
+        "/del\\_all \\- 从所有订阅删除关键词\n" +
+        "/add\\_block \\- 添加全局屏蔽词（对所有订阅生效）\n" +
+        "/del\\_block \\- 删除全局屏蔽词\n" +
+        "/edit\\_set \\- 编辑关键词组（多个订阅共用的关键词）\n" +
+        "/link\\_set \\- 设置订阅引用的关键词组"
     
     // 转义特殊字符，但保持命令格式
     helpText = strings.ReplaceAll(helpText, "!", "\\!")
@@ -315,9 +487,7 @@ func (b *Bot) handleStart(chatID int64) {
     
     msg := tgbotapi.NewMessage(chatID, helpText)
     msg.ParseMode = "MarkdownV2"
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleView(chatID int64, userID int64) {
@@ -333,14 +503,19 @@ func (b *Bot) handleView(chatID int64, userID int64) {
             tgbotapi.NewInlineKeyboardButtonData("📊 查看推送统计", "stats"),
             tgbotapi.NewInlineKeyboardButtonData("ℹ️ 获取当前版本", "version"),
         ),
+        tgbotapi.NewInlineKeyboardRow(
+            tgbotapi.NewInlineKeyboardButtonData("🩺 订阅健康状态", "health"),
+            tgbotapi.NewInlineKeyboardButtonData("🧪 试运行过滤", "test"),
+        ),
+        tgbotapi.NewInlineKeyboardRow(
+            tgbotapi.NewInlineKeyboardButtonData("🖼 预览消息模板", "preview"),
+        ),
     )
 
     msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(text))
     msg.ParseMode = "MarkdownV2"
     msg.ReplyMarkup = keyboard
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleEditCommand(chatID int64, userID int64) {
@@ -360,14 +535,20 @@ func (b *Bot) handleEditCommand(chatID int64, userID int64) {
             tgbotapi.NewInlineKeyboardButtonData("📝 添加全局关键词", "add_all"),
             tgbotapi.NewInlineKeyboardButtonData("🗑️ 删除全局关键词", "del_all"),
         ),
+        tgbotapi.NewInlineKeyboardRow(
+            tgbotapi.NewInlineKeyboardButtonData("🚫 添加屏蔽词", "add_block"),
+            tgbotapi.NewInlineKeyboardButtonData("♻️ 删除屏蔽词", "del_block"),
+        ),
+        tgbotapi.NewInlineKeyboardRow(
+            tgbotapi.NewInlineKeyboardButtonData("📚 编辑关键词组", "edit_set"),
+            tgbotapi.NewInlineKeyboardButtonData("🔗 引用关键词组", "link_set"),
+        ),
     )
 
     msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(text))
     msg.ParseMode = "MarkdownV2"
     msg.ReplyMarkup = keyboard
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleConfig(chatID int64) {
@@ -399,9 +580,7 @@ func (b *Bot) handleAdd(chatID int64, userID int64) {
     
     msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(message))
     msg.ParseMode = "MarkdownV2"
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleEdit(chatID int64, userID int64) {
@@ -411,13 +590,11 @@ func (b *Bot) handleEdit(chatID int64, userID int64) {
     }
     b.userState[userID] = "edit_index"
     message := b.listSubscriptions()
-    message += "\n请输入要编辑的RSS订阅编号："
+    message += "\n请输入要编辑的RSS订阅ID（或列表编号）："
     
     msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(message))
     msg.ParseMode = "MarkdownV2"
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleDelete(chatID int64, userID int64) {
@@ -427,13 +604,11 @@ func (b *Bot) handleDelete(chatID int64, userID int64) {
     }
     b.userState[userID] = "delete"
     message := b.listSubscriptions()
-    message += "\n请输入要删除的RSS订阅编号："
+    message += "\n请输入要删除的RSS订阅ID（或列表编号）："
     
     msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(message))
     msg.ParseMode = "MarkdownV2"
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleToggle(chatID int64, userID int64) {
@@ -461,16 +636,14 @@ func (b *Bot) handleToggle(chatID int64, userID int64) {
             }
         }
         
-        message += fmt.Sprintf("%d. %s %s [%s] - %s\n", 
-            i+1, statusIcon, statusText, rss.Group, urlDisplay)
+        message += fmt.Sprintf("%d. [%s] %s %s [%s] - %s\n", 
+            i+1, rss.ID, statusIcon, statusText, rss.Group, urlDisplay)
     }
-    message += "\n请输入要切换状态的RSS订阅编号："
+    message += "\n请输入要切换状态的RSS订阅ID（或列表编号）："
     
     msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(message))
     msg.ParseMode = "MarkdownV2"
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleList(chatID int64) {
@@ -497,8 +670,201 @@ func (b *Bot) handleStats(chatID int64) {
     msg.ParseMode = "MarkdownV2"  // 设置解析模式为 MarkdownV2
     
     // 发送消息
-    if _, err := b.api.Send(msg); err != nil {
+    b.queueReply(msg, func(err error) {
         log.Printf("发送统计信息失败: %v", err)
+    })
+}
+
+func (b *Bot) handleHealth(chatID int64) {
+    if b.healthHandler == nil {
+        b.sendMessage(chatID, "暂无订阅健康状态信息")
+        return
+    }
+
+    healthList := b.healthHandler()
+    if len(healthList) == 0 {
+        b.sendMessage(chatID, "当前没有RSS订阅")
+        return
+    }
+
+    message := "订阅健康状态:\n"
+    failing := 0
+    for _, h := range healthList {
+        statusIcon := "🟢"
+        switch {
+        case h.Alerted:
+            statusIcon = "🔴"
+        case !h.Healthy():
+            statusIcon = "🟡"
+        case h.LastSuccess.IsZero():
+            statusIcon = "⚪"
+        }
+        if !h.Healthy() {
+            failing++
+        }
+
+        message += fmt.Sprintf("%s [%s] %s | %s\n", statusIcon, h.SubscriptionID, h.Group, h.URL)
+        lastSuccess := "从未成功"
+        if !h.LastSuccess.IsZero() {
+            lastSuccess = h.LastSuccess.Format("2006-01-02 15:04:05")
+        }
+        message += fmt.Sprintf("   ✅ 最后成功: %s\n", lastSuccess)
+        if !h.Healthy() {
+            message += fmt.Sprintf("   ❌ 连续失败: %d 次 | 状态码: %d\n   📝 错误: %s\n",
+                h.ConsecutiveFailures, h.LastStatus, h.LastError)
+            if !h.NextAttempt.IsZero() {
+                message += fmt.Sprintf("   ⏳ 下次重试: %s\n", h.NextAttempt.Format("2006-01-02 15:04:05"))
+            }
+        }
+    }
+    message += fmt.Sprintf("\n共 %d 个订阅URL，异常 %d 个", len(healthList), failing)
+
+    b.sendMessage(chatID, message)
+}
+
+// dryRunMaxItems 试运行时每个URL最多展示的文章数
+const dryRunMaxItems = 50
+
+// handleTest 立即抓取订阅或URL并试运行关键词过滤，不推送也不标记文章。
+// args 为订阅ID、列表编号或URL，URL后可再加订阅ID以使用该订阅的规则
+func (b *Bot) handleTest(chatID int64, userID int64, args string) {
+    if !b.isAdmin(userID) {
+        b.sendMessage(chatID, "您不是系统管理员，无法操作")
+        return
+    }
+    if b.dryRunHandler == nil {
+        b.sendMessage(chatID, "试运行功能暂不可用")
+        return
+    }
+
+    fields := strings.Fields(args)
+    if len(fields) == 0 {
+        b.userState[userID] = "test_target"
+        b.sendMessage(chatID, b.listSubscriptions()+"\n请输入要试运行的订阅ID、列表编号或URL（URL后可加订阅ID，使用该订阅的规则）：")
+        return
+    }
+
+    var id, url string
+    if strings.HasPrefix(fields[0], "http://") || strings.HasPrefix(fields[0], "https://") {
+        url = fields[0]
+        if len(fields) > 1 {
+            if id = b.resolveSubscription(fields[1]); id == "" {
+                b.sendMessage(chatID, fmt.Sprintf("无效的订阅ID或编号：%s", fields[1]))
+                return
+            }
+        }
+    } else if id = b.resolveSubscription(fields[0]); id == "" {
+        b.sendMessage(chatID, fmt.Sprintf("无效的订阅ID、编号或URL：%s", fields[0]))
+        return
+    }
+
+    b.sendMessage(chatID, "🧪 正在抓取并试运行，请稍候...")
+    // 抓取可能较慢，避免阻塞其他命令
+    go func() {
+        results, err := b.dryRunHandler(id, url)
+        if err != nil {
+            b.sendText(chatID, fmt.Sprintf("试运行失败：%v", err))
+            return
+        }
+        b.sendLongMessage(chatID, formatDryRunResults(results))
+    }()
+}
+
+// formatDryRunResults 生成试运行结果说明：每篇文章是否会推送以及原因
+func formatDryRunResults(results []rss.DryRunResult) string {
+    message := "🧪 试运行结果（不会推送或标记任何文章）\n"
+    for _, result := range results {
+        message += "\n"
+        if result.SubscriptionID != "" {
+            message += fmt.Sprintf("📡 [%s] %s\n", result.SubscriptionID, result.URL)
+        } else {
+            message += fmt.Sprintf("📡 %s\n（未关联订阅，仅检查全局屏蔽词）\n", result.URL)
+        }
+        if result.Err != nil {
+            message += fmt.Sprintf("❌ 抓取失败：%v\n", result.Err)
+            continue
+        }
+
+        matched := 0
+        for _, item := range result.Items {
+            if item.Matched {
+                matched++
+            }
+        }
+        message += fmt.Sprintf("📰 %s | 共 %d 篇，匹配 %d 篇\n", result.FeedTitle, len(result.Items), matched)
+
+        for i, item := range result.Items {
+            if i >= dryRunMaxItems {
+                message += fmt.Sprintf("... 其余 %d 篇未显示\n", len(result.Items)-dryRunMaxItems)
+                break
+            }
+            message += formatDryRunItem(item, result.MinScore)
+        }
+    }
+    return message
+}
+
+// formatDryRunItem 生成单篇文章的试运行说明
+func formatDryRunItem(item rss.DryRunItem, minScore float64) string {
+    var icon, reason string
+    switch {
+    case item.Matched && len(item.Keywords) == 0:
+        icon, reason = "✅", "无关键词过滤"
+    case item.Matched:
+        icon, reason = "✅", fmt.Sprintf("命中: %s（评分 %s）", strings.Join(item.Keywords, ", "), filter.FormatWeight(item.Score))
+    case len(item.Excluded) > 0:
+        icon, reason = "🚫", fmt.Sprintf("命中%s: %s", item.ExcludedBy, strings.Join(item.Excluded, ", "))
+    case len(item.Keywords) > 0 || len(item.Penalties) > 0:
+        icon, reason = "📉", fmt.Sprintf("评分不足: %s（需要大于 %s），命中: %s", filter.FormatWeight(item.Score), filter.FormatWeight(minScore), strings.Join(item.Keywords, ", "))
+    default:
+        icon, reason = "⚪", "未命中关键词"
+    }
+
+    line := fmt.Sprintf("%s %s\n   %s\n", icon, item.Title, reason)
+    if len(item.Penalties) > 0 {
+        line += fmt.Sprintf("   ➖ 减分: %s\n", strings.Join(item.Penalties, ", "))
+    }
+    if len(item.FuzzyHits) > 0 {
+        line += fmt.Sprintf("   🌀 模糊匹配: %s\n", strings.Join(item.FuzzyHits, ", "))
+    }
+    if item.Stale {
+        line += "   ⏳ 超过最大时效，实际运行时不会推送\n"
+    }
+    if item.Sent {
+        line += "   📨 已推送过，实际运行时会跳过\n"
+    }
+    return line
+}
+
+// sendLongMessage 按行拆分并立即发送超过 Telegram 长度限制的消息，调用时不持有配置锁
+func (b *Bot) sendLongMessage(chatID int64, text string) {
+    // 转义后长度会增加，预留足够的余量
+    const maxRunes = 3000
+    var chunk strings.Builder
+    chunkRunes := 0
+    for _, line := range strings.SplitAfter(text, "\n") {
+        lineRunes := len([]rune(line))
+        if chunkRunes > 0 && chunkRunes+lineRunes > maxRunes {
+            b.sendText(chatID, chunk.String())
+            chunk.Reset()
+            chunkRunes = 0
+        }
+        chunk.WriteString(line)
+        chunkRunes += lineRunes
+    }
+    if chunkRunes > 0 {
+        b.sendText(chatID, chunk.String())
+    }
+}
+
+// SendAdminAlert 向所有管理员发送提醒消息
+func (b *Bot) SendAdminAlert(message string) {
+    b.configMu.RLock()
+    adminIDs := append([]int64(nil), b.adminIDs()...)
+    b.configMu.RUnlock()
+
+    for _, adminID := range adminIDs {
+        b.sendText(adminID, message)
     }
 }
 
@@ -540,7 +906,6 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
             return
         }
     case "add_url":
-        b.userState[userID] = "add_interval"
         urls := strings.Split(text, ",")
         // 清理URL列表
         cleanURLs := make([]string, 0)
@@ -551,108 +916,49 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
             }
         }
         // 创建新的RSSEntry并添加到配置中，默认允许部分匹配
+        id := b.config.NewSubscriptionID(cleanURLs)
         b.config.RSS = append(b.config.RSS, config.RSSEntry{
+            ID:             id,
             URLs:           cleanURLs,
             AllowPartMatch: true,  // 默认允许部分匹配
             Enabled:        true,  // 默认启用订阅
         })
+        b.userState[userID] = "add_interval_" + id
         b.sendMessage(chatID, "请输入订阅的更新间隔（秒）：")
-    case "add_interval":
-        interval, err := strconv.Atoi(text)
-        if err != nil {
-            b.sendMessage(chatID, "无效的间隔时间，请输入一个整数。")
-            return
-        }
-        lastIndex := len(b.config.RSS) - 1
-        if lastIndex >= 0 {
-            b.config.RSS[lastIndex].Interval = interval
-            b.userState[userID] = "add_keywords"
-            b.sendMessage(chatID, "请输入关键词（用空格分隔）：\n1: 保持原有关键词\n2: 不设置关键词（将推送所有新文章）\n或直接输入新的关键词")
-        } else {
-            b.sendMessage(chatID, "添加订阅失败：找不到要编辑的订阅")
-            delete(b.userState, userID)
-        }
-    case "add_keywords":
-        lastIndex := len(b.config.RSS) - 1
-        if lastIndex >= 0 {
-            switch text {
-            case "1":
-                // 保持原有关键词，不做任何修改
-            case "2":
-                // 清空关键词，将推送所有新文章
-                b.config.RSS[lastIndex].Keywords = []string{}
-            default:
-                // 使用新输入的关键词
-                keywords := strings.Fields(text)
-                b.config.RSS[lastIndex].Keywords = keywords
-            }
-            b.userState[userID] = "add_group"
-            b.sendMessage(chatID, "请输入组名：")
-        } else {
-            b.sendMessage(chatID, "添加订阅失败：找不到要编辑的订阅")
-            delete(b.userState, userID)
-        }
-    case "add_group":
-        lastIndex := len(b.config.RSS) - 1
-        if lastIndex >= 0 {
-            b.config.RSS[lastIndex].Group = text
-            b.userState[userID] = "add_part_match"
-            b.sendMessage(chatID, "是否允许部分关键词匹配？\n1: 允许（如：关键词\"go\"可以匹配到\"golang\"）\n2: 不允许（仅匹配完整单词）\n请输入选项编号(1或2)：")
-        } else {
-            b.sendMessage(chatID, "添加订阅失败：找不到要编辑的订阅")
-            delete(b.userState, userID)
-        }
-    case "add_part_match":
-        lastIndex := len(b.config.RSS) - 1
-        if lastIndex >= 0 {
-            switch text {
-            case "1":
-                b.config.RSS[lastIndex].AllowPartMatch = true
-            case "2":
-                b.config.RSS[lastIndex].AllowPartMatch = false
-            default:
-                b.sendMessage(chatID, "无效的选项，请输入1或2：")
-                return
-            }
-            delete(b.userState, userID)
-            if err := b.config.Save(b.configFile); err != nil {
-                b.sendMessage(chatID, "添加订阅成功，但保存配置失败。")
-            } else {
-                b.sendMessage(chatID, "成功添加RSS订阅。")
-                b.updateRSSHandler()
-            }
-        } else {
-            b.sendMessage(chatID, "添加订阅失败：找不到要编辑的订阅")
-            delete(b.userState, userID)
-        }
     case "edit_index":
-        index, err := strconv.Atoi(text)
-        if err != nil || index < 1 || index > len(b.config.RSS) {
-            b.sendMessage(chatID, "无效的编号。请使用 /edit 重新开始。")
+        id := b.resolveSubscription(text)
+        if id == "" {
+            b.sendMessage(chatID, "无效的订阅ID或编号。请使用 /edit 重新开始。")
             delete(b.userState, userID)
             return
         }
-        b.userState[userID] = fmt.Sprintf("edit_url_%d", index-1)
-        b.sendMessage(chatID, fmt.Sprintf("当前URL列表为：\n%s\n请输入新的URL列表（多个URL用英文逗号分隔，如不修改请输入1）：", 
-            strings.Join(b.config.RSS[index-1].URLs, "\n")))
+        index := b.config.FindRSS(id)
+        b.userState[userID] = "edit_url_" + id
+        b.sendMessage(chatID, fmt.Sprintf("正在编辑订阅 [%s]\n当前URL列表为：\n%s\n请输入新的URL列表（多个URL用英文逗号分隔，如不修改请输入1）：", 
+            id, strings.Join(b.config.RSS[index].URLs, "\n")))
     case "delete":
-        index, err := strconv.Atoi(text)
-        if err != nil || index < 1 || index > len(b.config.RSS) {
-            b.sendMessage(chatID, "无效的编号。请使用 /delete 重新开始。")
+        id := b.resolveSubscription(text)
+        if id == "" {
+            b.sendMessage(chatID, "无效的订阅ID或编号。请使用 /delete 重新开始。")
             delete(b.userState, userID)
             return
         }
-        deletedRSS := b.config.RSS[index-1]
-        b.config.RSS = append(b.config.RSS[:index-1], b.config.RSS[index:]...)
+        index := b.config.FindRSS(id)
+        deletedRSS := b.config.RSS[index]
+        b.config.RSS = append(b.config.RSS[:index], b.config.RSS[index+1:]...)
         if err := b.config.Save(b.configFile); err != nil {
             b.sendMessage(chatID, "删除订阅成功，但保存配置失败。")
         } else {
-            b.sendMessage(chatID, fmt.Sprintf("成功删除订阅: %v", deletedRSS.URLs))
-            b.updateRSSHandler()
+            b.sendMessage(chatID, fmt.Sprintf("成功删除订阅 [%s]: %v", deletedRSS.ID, deletedRSS.URLs))
+            b.updateRSSHandler(b.config)
         }
         delete(b.userState, userID)
     case "add_all_keywords":
-        keywords := strings.Fields(text)
+        keywords, err := parseKeywordInput(text)
+        if err != nil {
+            b.sendMessage(chatID, fmt.Sprintf("关键词无效：%v\n请重新输入：", err))
+            return
+        }
         if len(keywords) == 0 {
             b.sendMessage(chatID, "请输入至少一个关键词。")
             return
@@ -677,12 +983,16 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
             b.sendMessage(chatID, "添加关键词成功，但保存配置失败。")
         } else {
             b.sendMessage(chatID, fmt.Sprintf("成功向所有订阅添加关键词：%v", keywords))
-            b.updateRSSHandler()
+            b.updateRSSHandler(b.config)
         }
         delete(b.userState, userID)
         
     case "del_all_keywords":
-        keywords := strings.Fields(text)
+        keywords, err := parseKeywordInput(text)
+        if err != nil {
+            b.sendMessage(chatID, fmt.Sprintf("关键词无效：%v\n请重新输入：", err))
+            return
+        }
         if len(keywords) == 0 {
             b.sendMessage(chatID, "请输入至少一个关键词。")
             return
@@ -708,12 +1018,167 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
             b.sendMessage(chatID, "删除关键词成功，但保存配置失败。")
         } else {
             b.sendMessage(chatID, fmt.Sprintf("成功从所有订阅中删除关键词：%v", keywords))
-            b.updateRSSHandler()
+            b.updateRSSHandler(b.config)
+        }
+        delete(b.userState, userID)
+    case "add_blocklist":
+        keywords, err := parseKeywordInput(text)
+        if err != nil {
+            b.sendMessage(chatID, fmt.Sprintf("屏蔽词无效：%v\n请重新输入：", err))
+            return
+        }
+        if len(keywords) == 0 {
+            b.sendMessage(chatID, "请输入至少一个屏蔽词。")
+            return
+        }
+        
+        // 添加到全局屏蔽词（避免重复）
+        existing := make(map[string]bool)
+        for _, k := range b.config.Blocklist {
+            existing[strings.ToLower(k)] = true
+        }
+        for _, newKeyword := range keywords {
+            if !existing[strings.ToLower(newKeyword)] {
+                b.config.Blocklist = append(b.config.Blocklist, newKeyword)
+                existing[strings.ToLower(newKeyword)] = true
+            }
+        }
+        
+        if err := b.config.Save(b.configFile); err != nil {
+            b.sendMessage(chatID, "添加屏蔽词成功，但保存配置失败。")
+        } else {
+            b.sendMessage(chatID, fmt.Sprintf("成功添加全局屏蔽词：%v\n当前屏蔽词：%v", keywords, b.config.Blocklist))
+            b.updateRSSHandler(b.config)
+        }
+        delete(b.userState, userID)
+        
+    case "del_blocklist":
+        keywords, err := parseKeywordInput(text)
+        if err != nil {
+            b.sendMessage(chatID, fmt.Sprintf("屏蔽词无效：%v\n请重新输入：", err))
+            return
+        }
+        if len(keywords) == 0 {
+            b.sendMessage(chatID, "请输入至少一个屏蔽词。")
+            return
+        }
+        
+        // 从全局屏蔽词中删除
+        keywordsToRemove := make(map[string]bool)
+        for _, k := range keywords {
+            keywordsToRemove[strings.ToLower(k)] = true
+        }
+        newBlocklist := make([]string, 0)
+        for _, k := range b.config.Blocklist {
+            if !keywordsToRemove[strings.ToLower(k)] {
+                newBlocklist = append(newBlocklist, k)
+            }
+        }
+        b.config.Blocklist = newBlocklist
+        
+        if err := b.config.Save(b.configFile); err != nil {
+            b.sendMessage(chatID, "删除屏蔽词成功，但保存配置失败。")
+        } else {
+            b.sendMessage(chatID, fmt.Sprintf("成功删除全局屏蔽词：%v\n当前屏蔽词：%v", keywords, b.config.Blocklist))
+            b.updateRSSHandler(b.config)
         }
         delete(b.userState, userID)
+    case "keyword_set_name":
+        name := strings.TrimSpace(text)
+        if err := config.ValidateKeywordSetName(name); err != nil {
+            b.sendMessage(chatID, fmt.Sprintf("%v\n请重新输入：", err))
+            return
+        }
+        b.userState[userID] = keywordSetState + name
+        current := "（新建）"
+        if keywords, ok := b.config.KeywordSets[name]; ok {
+            current = fmt.Sprintf("%v", keywords)
+        }
+        b.sendMessage(chatID, fmt.Sprintf("关键词组 %s 当前的关键词：%s\n请输入新的关键词（用空格分隔，将替换原有关键词）：\n%s\n1: 保持不变\n2: 删除该关键词组（同时移除订阅中的引用）",
+            name, current, keywordExpressionHelp))
+    case "test_target":
+        delete(b.userState, userID)
+        b.handleTest(chatID, userID, text)
+    case "preview_target":
+        delete(b.userState, userID)
+        b.handlePreview(chatID, userID, strings.TrimSpace(text))
+    case "link_set_index":
+        id := b.resolveSubscription(text)
+        if id == "" {
+            b.sendMessage(chatID, "无效的订阅ID或编号。请使用 /edit 重新开始。")
+            delete(b.userState, userID)
+            return
+        }
+        index := b.config.FindRSS(id)
+        b.userState[userID] = "link_sets_" + id
+        b.sendMessage(chatID, fmt.Sprintf("订阅 [%s] 当前引用的关键词组：%v\n可用的关键词组：%s\n请输入要引用的关键词组名称（用空格分隔，将替换原有引用），输入 0 取消所有引用：",
+            id, b.config.RSS[index].KeywordSets, strings.Join(b.config.KeywordSetNames(), ", ")))
     default:
-        if strings.HasPrefix(b.userState[userID], "edit_url_") {
-            index, _ := strconv.Atoi(strings.TrimPrefix(b.userState[userID], "edit_url_"))
+        if strings.HasPrefix(b.userState[userID], keywordSetState) {
+            b.handleKeywordSetInput(chatID, userID, strings.TrimPrefix(b.userState[userID], keywordSetState), text)
+            return
+        }
+        step, id := splitSubscriptionState(b.userState[userID])
+        if step == "" {
+            return
+        }
+        index := b.config.FindRSS(id)
+        if index < 0 {
+            b.sendMessage(chatID, fmt.Sprintf("订阅 [%s] 不存在，可能已被删除。", id))
+            delete(b.userState, userID)
+            return
+        }
+
+        switch step {
+        case "add_interval":
+            interval, err := strconv.Atoi(text)
+            if err != nil || interval <= 0 {
+                b.sendMessage(chatID, "无效的间隔时间，请输入一个大于0的整数。")
+                return
+            }
+            b.config.RSS[index].Interval = interval
+            b.userState[userID] = "add_keywords_" + id
+            b.sendMessage(chatID, "请输入关键词（用空格分隔）：\n"+keywordExpressionHelp+"\n1: 保持原有关键词\n2: 不设置关键词（将推送所有新文章）\n或直接输入新的关键词")
+        case "add_keywords":
+            switch text {
+            case "1":
+                // 保持原有关键词，不做任何修改
+            case "2":
+                // 清空关键词，将推送所有新文章
+                b.config.RSS[index].Keywords = []string{}
+            default:
+                // 使用新输入的关键词
+                keywords, err := parseKeywordInput(text)
+                if err != nil {
+                    b.sendMessage(chatID, fmt.Sprintf("关键词无效：%v\n请重新输入：", err))
+                    return
+                }
+                b.config.RSS[index].Keywords = keywords
+            }
+            b.userState[userID] = "add_group_" + id
+            b.sendMessage(chatID, "请输入组名：")
+        case "add_group":
+            b.config.RSS[index].Group = text
+            b.userState[userID] = "add_part_match_" + id
+            b.sendMessage(chatID, "是否允许部分关键词匹配？\n1: 允许（如：关键词\"go\"可以匹配到\"golang\"）\n2: 不允许（仅匹配完整单词）\n请输入选项编号(1或2)：")
+        case "add_part_match":
+            switch text {
+            case "1":
+                b.config.RSS[index].AllowPartMatch = true
+            case "2":
+                b.config.RSS[index].AllowPartMatch = false
+            default:
+                b.sendMessage(chatID, "无效的选项，请输入1或2：")
+                return
+            }
+            delete(b.userState, userID)
+            if err := b.config.Save(b.configFile); err != nil {
+                b.sendMessage(chatID, "添加订阅成功，但保存配置失败。")
+            } else {
+                b.sendMessage(chatID, fmt.Sprintf("成功添加RSS订阅 [%s]。", id))
+                b.updateRSSHandler(b.config)
+            }
+        case "edit_url":
             if text != "1" {
                 urls := strings.Split(text, ",")
                 // 清理URL列表
@@ -726,22 +1191,20 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
                 }
                 b.config.RSS[index].URLs = cleanURLs
             }
-            b.userState[userID] = fmt.Sprintf("edit_interval_%d", index)
+            b.userState[userID] = "edit_interval_" + id
             b.sendMessage(chatID, fmt.Sprintf("当前间隔为：%d秒\n请输入新的间隔时间（秒）如不修改请输入1）：", b.config.RSS[index].Interval))
-        } else if strings.HasPrefix(b.userState[userID], "edit_interval_") {
-            index, _ := strconv.Atoi(strings.TrimPrefix(b.userState[userID], "edit_interval_"))
+        case "edit_interval":
             if text != "1" {
                 interval, err := strconv.Atoi(text)
-                if err != nil {
-                    b.sendMessage(chatID, "无效的间隔时间，请输入一个整数。不修改请输入1。")
+                if err != nil || interval <= 0 {
+                    b.sendMessage(chatID, "无效的间隔时间，请输入一个大于0的整数。不修改请输入1。")
                     return
                 }
                 b.config.RSS[index].Interval = interval
             }
-            b.userState[userID] = fmt.Sprintf("edit_keywords_%d", index)
-            b.sendMessage(chatID, fmt.Sprintf("当前关键词为：%v\n请输入新的关键词（用空格分隔）：\n1: 保持原有关键词\n2: 不设置关键词（将推送所有新文章）\n或直接输入新的关键词", b.config.RSS[index].Keywords))
-        } else if strings.HasPrefix(b.userState[userID], "edit_keywords_") {
-            index, _ := strconv.Atoi(strings.TrimPrefix(b.userState[userID], "edit_keywords_"))
+            b.userState[userID] = "edit_keywords_" + id
+            b.sendMessage(chatID, fmt.Sprintf("当前关键词为：%v\n请输入新的关键词（用空格分隔）：\n%s\n1: 保持原有关键词\n2: 不设置关键词（将推送所有新文章）\n或直接输入新的关键词", b.config.RSS[index].Keywords, keywordExpressionHelp))
+        case "edit_keywords":
             switch text {
             case "1":
                 // 保持原有关键词，不做任何修改
@@ -750,21 +1213,42 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
                 b.config.RSS[index].Keywords = []string{}
             default:
                 // 使用新输入的关键词
-                keywords := strings.Fields(text)
+                keywords, err := parseKeywordInput(text)
+                if err != nil {
+                    b.sendMessage(chatID, fmt.Sprintf("关键词无效：%v\n请重新输入：", err))
+                    return
+                }
                 b.config.RSS[index].Keywords = keywords
             }
-            b.userState[userID] = fmt.Sprintf("edit_group_%d", index)
+            b.userState[userID] = "edit_group_" + id
             b.sendMessage(chatID, fmt.Sprintf("当前组名为：%s\n请输入新的组名（如不修改请输入1）：", b.config.RSS[index].Group))
-        } else if strings.HasPrefix(b.userState[userID], "edit_group_") {
-            index, _ := strconv.Atoi(strings.TrimPrefix(b.userState[userID], "edit_group_"))
+        case "edit_group":
             if text != "1" {
                 b.config.RSS[index].Group = text
             }
-            b.userState[userID] = fmt.Sprintf("edit_part_match_%d", index)
+            b.userState[userID] = "edit_part_match_" + id
             b.sendMessage(chatID, fmt.Sprintf("当前部分匹配设置：%v\n是否允许部分关键词匹配？\n1: 允许（如：关键词\"go\"可以匹配到\"golang\"）\n2: 不允许（仅匹配完整单词）\n3: 保持不变\n请输入选项编号(1-3)：", 
                 b.config.RSS[index].AllowPartMatch))
-        } else if strings.HasPrefix(b.userState[userID], "edit_part_match_") {
-            index, _ := strconv.Atoi(strings.TrimPrefix(b.userState[userID], "edit_part_match_"))
+        case "link_sets":
+            var sets []string
+            if text != "0" {
+                for _, name := range strings.Fields(text) {
+                    if _, ok := b.config.KeywordSets[name]; !ok {
+                        b.sendMessage(chatID, fmt.Sprintf("关键词组 %s 不存在，请重新输入：", name))
+                        return
+                    }
+                    sets = append(sets, name)
+                }
+            }
+            b.config.RSS[index].KeywordSets = sets
+            delete(b.userState, userID)
+            if err := b.config.Save(b.configFile); err != nil {
+                b.sendMessage(chatID, "设置关键词组成功，但保存配置失败。")
+            } else {
+                b.sendMessage(chatID, fmt.Sprintf("订阅 [%s] 当前引用的关键词组：%v", id, sets))
+                b.updateRSSHandler(b.config)
+            }
+        case "edit_part_match":
             switch text {
             case "1":
                 b.config.RSS[index].AllowPartMatch = true
@@ -781,7 +1265,7 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
                 b.sendMessage(chatID, "编辑订阅成功，但保存配置失败。")
             } else {
                 b.sendMessage(chatID, "成功编辑RSS订阅。")
-                b.updateRSSHandler()
+                b.updateRSSHandler(b.config)
             }
         }
     case "add_user":
@@ -838,15 +1322,15 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
         }
         delete(b.userState, userID)
     case "toggle_subscription":
-        index, err := strconv.Atoi(text)
-        if err != nil || index < 1 || index > len(b.config.RSS) {
-            b.sendMessage(chatID, "无效的编号。请输入正确的RSS订阅编号。")
+        id := b.resolveSubscription(text)
+        if id == "" {
+            b.sendMessage(chatID, "无效的订阅ID或编号。请输入正确的RSS订阅ID或编号。")
             delete(b.userState, userID)
             return
         }
         
         // 切换启用状态
-        rssIndex := index - 1
+        rssIndex := b.config.FindRSS(id)
         b.config.RSS[rssIndex].Enabled = !b.config.RSS[rssIndex].Enabled
         
         // 保存配置
@@ -867,9 +1351,9 @@ func (b *Bot) handleUserInput(message *tgbotapi.Message) {
                 }
             }
             
-            b.sendMessage(chatID, fmt.Sprintf("成功将订阅 [%s] %s 设为 %s", 
-                b.config.RSS[rssIndex].Group, urlDisplay, statusText))
-            b.updateRSSHandler()
+            b.sendMessage(chatID, fmt.Sprintf("成功将订阅 [%s] [%s] %s 设为 %s", 
+                id, b.config.RSS[rssIndex].Group, urlDisplay, statusText))
+            b.updateRSSHandler(b.config)
         }
         delete(b.userState, userID)
     }
@@ -879,6 +1363,49 @@ func (b *Bot) getConfig() string {
     config := "当前配置信息：\n"
     config += fmt.Sprintf("用户: %v\n", b.users)
     config += fmt.Sprintf("频道: %v\n", b.channels)
+    for _, forum := range formatForums(b.config.Telegram.Forums) {
+        config += fmt.Sprintf("📌 分组话题 %s\n", forum)
+    }
+    if b.config.Telegram.Template != "" {
+        config += "🖼 全局消息模板: 已设置（/preview 0 预览）\n"
+    }
+    if b.config.Telegram.ParseMode != "" {
+        config += fmt.Sprintf("📝 消息格式: %s\n", b.config.Telegram.ParseMode)
+    }
+    targets := make([]string, 0, len(b.config.Telegram.ParseModes))
+    for target := range b.config.Telegram.ParseModes {
+        targets = append(targets, target)
+    }
+    sort.Strings(targets)
+    for _, target := range targets {
+        config += fmt.Sprintf("📝 %s 的消息格式: %s\n", target, b.config.Telegram.ParseModes[target])
+    }
+    if len(b.config.Blocklist) > 0 {
+        config += fmt.Sprintf("🚫 全局屏蔽词: %s\n", strings.Join(b.config.Blocklist, ", "))
+    }
+    for _, name := range b.config.KeywordSetNames() {
+        config += fmt.Sprintf("📚 关键词组 %s: %s\n", name, strings.Join(b.config.KeywordSets[name], ", "))
+    }
+    if normalizeSynonyms := b.config.NormalizeSynonyms(); len(normalizeSynonyms) > 0 {
+        aliases := make([]string, 0, len(normalizeSynonyms))
+        for alias := range normalizeSynonyms {
+            aliases = append(aliases, alias)
+        }
+        sort.Strings(aliases)
+        synonyms := make([]string, len(aliases))
+        for i, alias := range aliases {
+            synonyms[i] = alias + " → " + normalizeSynonyms[alias]
+        }
+        config += fmt.Sprintf("🔁 同义词: %s\n", strings.Join(synonyms, ", "))
+    }
+    groups := make([]string, 0, len(b.config.GroupRoutes))
+    for group := range b.config.GroupRoutes {
+        groups = append(groups, group)
+    }
+    sort.Strings(groups)
+    for _, group := range groups {
+        config += fmt.Sprintf("📮 分组 %s 推送目标: %s\n", group, formatRoute(b.config.GroupRoutes[group]))
+    }
     config += "RSS订阅:\n"
     for i, rss := range b.config.RSS {
         // 添加启用状态图标
@@ -887,7 +1414,7 @@ func (b *Bot) getConfig() string {
             statusIcon = "🟢" // 启用状态
         }
         
-        config += fmt.Sprintf("%d. %s 📡 URLs:\n", i+1, statusIcon)
+        config += fmt.Sprintf("%d. [%s] %s 📡 URLs:\n", i+1, rss.ID, statusIcon)
         for j, url := range rss.URLs {
             config += fmt.Sprintf("   %d) %s\n", j+1, url)  // 直接显示URL，不进行转义
         }
@@ -903,10 +1430,62 @@ func (b *Bot) getConfig() string {
             escapedGroup,
             escapeMarkdownV2Text(b.getPartMatchStatus(rss.AllowPartMatch)),
             escapeMarkdownV2Text(b.getEnabledStatus(rss.Enabled)))
+        if len(rss.ExcludeKeywords) > 0 {
+            config += fmt.Sprintf("   🚫 排除词: %s\n", strings.Join(rss.ExcludeKeywords, ", "))
+        }
+        if len(rss.KeywordSets) > 0 {
+            config += fmt.Sprintf("   📚 关键词组: %s\n", strings.Join(rss.KeywordSets, ", "))
+        }
+        if len(rss.MatchFields) > 0 {
+            config += fmt.Sprintf("   🔎 匹配字段: %s\n", strings.Join(rss.MatchFields, ", "))
+        }
+        if !rss.StripsPunctuation() {
+            config += "   ✏️ 标点: 保留\n"
+        }
+        if rss.FuzzyMatch {
+            config += "   🌀 模糊匹配: 开启\n"
+        }
+        if rss.MinScore > 0 {
+            config += fmt.Sprintf("   📈 分数阈值: 大于 %s\n", filter.FormatWeight(rss.MinScore))
+        }
+        if rss.Template != "" {
+            config += "   🖼 消息模板: 自定义\n"
+        }
+        if b.config.SendsImages(&b.config.RSS[i]) {
+            config += "   📷 发送图片: 开启\n"
+        }
+        if route := b.config.RouteFor(&b.config.RSS[i]); !route.IsEmpty() {
+            config += fmt.Sprintf("   📮 推送目标: %s\n", formatRoute(route))
+        }
+        for _, rule := range rss.Regex {
+            if rule.Weight != 0 {
+                config += fmt.Sprintf("   🧩 正则规则: %s → %s（权重 %s）\n", rule.Name, rule.Pattern, filter.FormatWeight(rule.Weight))
+            } else {
+                config += fmt.Sprintf("   🧩 正则规则: %s → %s\n", rule.Name, rule.Pattern)
+            }
+        }
     }
     return config
 }
 
+// formatRoute 格式化推送目标，未设置的部分显示为使用全局设置
+func formatRoute(route config.Route) string {
+    users, channels, webhooks := "全局", "全局", "全部"
+    if route.HasTelegram() {
+        users, channels = "无", "无"
+        if len(route.Users) > 0 {
+            users = strings.Join(route.Users, ", ")
+        }
+        if len(route.Channels) > 0 {
+            channels = strings.Join(route.Channels, ", ")
+        }
+    }
+    if len(route.Webhooks) > 0 {
+        webhooks = strings.Join(route.Webhooks, ", ")
+    }
+    return fmt.Sprintf("用户 %s；频道 %s；webhook %s", users, channels, webhooks)
+}
+
 func (b *Bot) listSubscriptions() string {
     list := "当前RSS订阅列表:\n"
     for i, rss := range b.config.RSS {
@@ -916,7 +1495,7 @@ func (b *Bot) listSubscriptions() string {
             statusIcon = "🟢" // 启用状态
         }
         
-        list += fmt.Sprintf("%d. %s 📡 URLs:\n", i+1, statusIcon)
+        list += fmt.Sprintf("%d. [%s] %s 📡 URLs:\n", i+1, rss.ID, statusIcon)
         for j, url := range rss.URLs {
             list += fmt.Sprintf("   %d) %s\n", j+1, url)  // 直接显示URL，不进行转义
         }
@@ -939,28 +1518,63 @@ func (b *Bot) listSubscriptions() string {
 
 func (b *Bot) getStats() string {
     dailyCount, weeklyCount, totalCount := b.stats.GetMessageCounts()
-    return fmt.Sprintf("推送统计:\n📊 今日推送: %s\n📈 本周推送: %s\n📋 总计推送: %s", 
+    message := fmt.Sprintf("推送统计:\n📊 今日推送: %s\n📈 本周推送: %s\n📋 总计推送: %s", 
         formatBoldText(strconv.Itoa(dailyCount)),
         formatBoldText(strconv.Itoa(weeklyCount)),
         formatBoldText(strconv.Itoa(totalCount)))
+
+    // 按订阅统计，仅显示当前配置中仍存在的订阅
+    lines := ""
+    for _, rss := range b.config.RSS {
+        count := b.stats.GetSubscriptionCount(rss.ID)
+        if count == 0 {
+            continue
+        }
+        lines += fmt.Sprintf("\n%s: %s", escapeMarkdownV2Text(fmt.Sprintf("[%s] %s", rss.ID, rss.Group)),
+            formatBoldText(strconv.Itoa(count)))
+    }
+    if lines != "" {
+        message += "\n\n按订阅统计:" + lines
+    }
+    return message
 }
 
-func (b *Bot) UpdateConfig(cfg *config.Config) {
+// UpdateConfig 重新加载配置文件，配置有变化时更新订阅并返回 true。
+// 加载时可能补充配置并写回文件，因此与机器人修改配置一样在配置写锁内进行
+func (b *Bot) UpdateConfig() (bool, error) {
+    b.configMu.Lock()
+    defer b.configMu.Unlock()
+
+    cfg, err := config.Load(b.configFile)
+    if err != nil {
+        return false, err
+    }
+    if b.config.Equal(cfg) {
+        return false, nil
+    }
     b.config = cfg
+    b.updateRSSHandler(cfg)
+    return true, nil
 }
 
+// handleVersion 获取最新版本需要访问网络，在后台进行，避免阻塞其他命令和推送
 func (b *Bot) handleVersion(chatID int64) {
+    go b.sendVersion(chatID)
+}
+
+// sendVersion 发送当前版本和最新版本，调用时不持有配置锁
+func (b *Bot) sendVersion(chatID int64) {
     // 获取当前版本
     currentVersion, err := b.getCurrentVersion()
     if err != nil {
-        b.sendMessage(chatID, fmt.Sprintf("获取当前版本失败：%v", err))
+        b.sendText(chatID, fmt.Sprintf("获取当前版本失败：%v", err))
         return
     }
 
     // 获取最新版本
     latestVersion, err := b.getLatestVersion()
     if err != nil {
-        b.sendMessage(chatID, fmt.Sprintf("获取最新版本失败：%v", err))
+        b.sendText(chatID, fmt.Sprintf("获取最新版本失败：%v", err))
         return
     }
 
@@ -992,9 +1606,12 @@ func (b *Bot) getCurrentVersion() (string, error) {
     return strings.TrimSpace(string(content)), nil
 }
 
+// versionClient 获取最新版本使用的 HTTP 客户端
+var versionClient = &http.Client{Timeout: 10 * time.Second}
+
 func (b *Bot) getLatestVersion() (string, error) {
     // 直接从远程获取最新版本
-    resp, err := http.Get("https://raw.githubusercontent.com/3377/rss2tg/refs/heads/main/version")
+    resp, err := versionClient.Get("https://raw.githubusercontent.com/3377/rss2tg/refs/heads/main/version")
     if err != nil {
         return "", fmt.Errorf("无法获取最新版本: %v", err)
     }
@@ -1018,7 +1635,7 @@ func (b *Bot) handleAddAll(chatID int64, userID int64) {
         return
     }
     b.userState[userID] = "add_all_keywords"
-    b.sendMessage(chatID, "请输入要添加到所有订阅的关键词（用空格分隔）：")
+    b.sendMessage(chatID, "请输入要添加到所有订阅的关键词（用空格分隔）：\n"+keywordExpressionHelp+"\n提示：多个订阅共用的关键词建议放入 📚 关键词组，修改一次即对所有引用的订阅生效")
 }
 
 func (b *Bot) handleDelAll(chatID int64, userID int64) {
@@ -1030,18 +1647,118 @@ func (b *Bot) handleDelAll(chatID int64, userID int64) {
     b.sendMessage(chatID, "请输入要从所有订阅中删除的关键词（用空格分隔）：")
 }
 
-// sendMessage 发送普通消息
+func (b *Bot) handleAddBlock(chatID int64, userID int64) {
+    if !b.isAdmin(userID) {
+        b.sendMessage(chatID, "您不是系统管理员，无法操作")
+        return
+    }
+    b.userState[userID] = "add_blocklist"
+    b.sendMessage(chatID, fmt.Sprintf("当前全局屏蔽词：%v\n命中屏蔽词的文章在所有订阅中都不会推送。\n请输入要添加的屏蔽词（用空格分隔）：\n%s", 
+        b.config.Blocklist, keywordExpressionHelp))
+}
+
+func (b *Bot) handleDelBlock(chatID int64, userID int64) {
+    if !b.isAdmin(userID) {
+        b.sendMessage(chatID, "您不是系统管理员，无法操作")
+        return
+    }
+    if len(b.config.Blocklist) == 0 {
+        b.sendMessage(chatID, "当前没有全局屏蔽词。")
+        return
+    }
+    b.userState[userID] = "del_blocklist"
+    b.sendMessage(chatID, fmt.Sprintf("当前全局屏蔽词：%v\n请输入要删除的屏蔽词（用空格分隔）：", b.config.Blocklist))
+}
+
+func (b *Bot) handleEditKeywordSet(chatID int64, userID int64) {
+    if !b.isAdmin(userID) {
+        b.sendMessage(chatID, "您不是系统管理员，无法操作")
+        return
+    }
+    b.userState[userID] = "keyword_set_name"
+    message := "当前关键词组：\n"
+    if len(b.config.KeywordSets) == 0 {
+        message += "无\n"
+    }
+    for _, name := range b.config.KeywordSetNames() {
+        message += fmt.Sprintf("%s: %v\n", name, b.config.KeywordSets[name])
+    }
+    message += "关键词组修改后对所有引用它的订阅立即生效。\n请输入要编辑的关键词组名称（输入新名称将创建关键词组）："
+    b.sendMessage(chatID, message)
+}
+
+func (b *Bot) handleLinkKeywordSet(chatID int64, userID int64) {
+    if !b.isAdmin(userID) {
+        b.sendMessage(chatID, "您不是系统管理员，无法操作")
+        return
+    }
+    if len(b.config.KeywordSets) == 0 {
+        b.sendMessage(chatID, "当前没有关键词组，请先通过 📚 编辑关键词组 创建。")
+        return
+    }
+    b.userState[userID] = "link_set_index"
+    b.sendMessage(chatID, b.listSubscriptions()+"\n请输入要设置关键词组的订阅ID（或列表编号）：")
+}
+
+// keywordSetState 编辑关键词组内容时的状态前缀，后接关键词组名称
+const keywordSetState = "keyword_set_keywords_"
+
+// handleKeywordSetInput 处理关键词组的新内容：保持不变、删除或替换为输入的关键词
+func (b *Bot) handleKeywordSetInput(chatID int64, userID int64, name string, text string) {
+    var reply string
+    switch text {
+    case "1":
+        delete(b.userState, userID)
+        b.sendMessage(chatID, "关键词组未修改。")
+        return
+    case "2":
+        b.config.RemoveKeywordSet(name)
+        reply = fmt.Sprintf("成功删除关键词组 %s", name)
+    default:
+        keywords, err := parseKeywordInput(text)
+        if err != nil {
+            b.sendMessage(chatID, fmt.Sprintf("关键词无效：%v\n请重新输入：", err))
+            return
+        }
+        if len(keywords) == 0 {
+            b.sendMessage(chatID, "请输入至少一个关键词。")
+            return
+        }
+        if b.config.KeywordSets == nil {
+            b.config.KeywordSets = make(map[string][]string)
+        }
+        b.config.KeywordSets[name] = keywords
+        reply = fmt.Sprintf("成功保存关键词组 %s：%v", name, keywords)
+    }
+    delete(b.userState, userID)
+
+    if err := b.config.Save(b.configFile); err != nil {
+        b.sendMessage(chatID, "修改关键词组成功，但保存配置失败。")
+    } else {
+        b.sendMessage(chatID, reply)
+        b.updateRSSHandler(b.config)
+    }
+}
+
+// sendMessage 回复普通消息，在处理消息时调用，释放配置锁后发送
 func (b *Bot) sendMessage(chatID int64, text string) {
-    // 转义特殊字符
-    text = escapeMarkdownV2Text(text)
-    
-    msg := tgbotapi.NewMessage(chatID, text)
-    msg.ParseMode = "MarkdownV2"
-    if _, err := b.api.Send(msg); err != nil {
+    b.queueReply(newTextMessage(chatID, text), nil)
+}
+
+// sendText 立即发送普通消息，用于后台任务和提醒等不持有配置锁的场景
+func (b *Bot) sendText(chatID int64, text string) {
+    if _, err := b.api.Send(newTextMessage(chatID, text)); err != nil {
         log.Printf("发送消息失败: %v", err)
     }
 }
 
+// newTextMessage 生成转义特殊字符后的普通消息
+func newTextMessage(chatID int64, text string) tgbotapi.MessageConfig {
+    msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(text))
+    msg.ParseMode = "MarkdownV2"
+    return msg
+}
+
 // 辅助函数：获取部分匹配状态的描述
 func (b *Bot) getPartMatchStatus(allowPartMatch bool) string {
     if allowPartMatch {
@@ -1075,9 +1792,7 @@ func (b *Bot) handleUsers(chatID int64, userID int64) {
     msg := tgbotapi.NewMessage(chatID, escapeMarkdownV2Text(text))
     msg.ParseMode = "MarkdownV2"
     msg.ReplyMarkup = keyboard
-    if _, err := b.api.Send(msg); err != nil {
-        log.Printf("发送消息失败: %v", err)
-    }
+    b.queueReply(msg, nil)
 }
 
 func (b *Bot) handleAddUser(chatID int64, userID int64) {
@@ -1127,6 +1842,76 @@ func (b *Bot) isAdmin(userID int64) bool {
     return false
 }
 
+// keywordExpressionHelp 关键词输入时的表达式说明
+const keywordExpressionHelp = "如需组合条件，可每行输入一条表达式，如：vps AND (优惠 OR 促销) AND NOT 测评\n以 re: 开头的行为正则规则，如：re:\\$\\d+/yr"
+
+// parseKeywordInput 解析用户输入的关键词：使用表达式语法或以 re: 开头的行作为一条规则，
+// 其余行按空格拆分为多个关键词
+func parseKeywordInput(text string) ([]string, error) {
+    keywords := make([]string, 0)
+    for _, line := range strings.Split(text, "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" {
+            continue
+        }
+        if !filter.IsRegex(line) && !filter.IsExpression(line) {
+            keywords = append(keywords, strings.Fields(line)...)
+            continue
+        }
+        if err := filter.Validate(line); err != nil {
+            return nil, err
+        }
+        keywords = append(keywords, line)
+    }
+    return keywords, nil
+}
+
+// subscriptionSteps 使用订阅ID作为后缀的多步输入状态
+var subscriptionSteps = []string{
+    "add_interval", "add_keywords", "add_group", "add_part_match",
+    "edit_url", "edit_interval", "edit_keywords", "edit_group", "edit_part_match",
+    "link_sets",
+}
+
+// splitSubscriptionState 将 "edit_url_<订阅ID>" 形式的状态拆分为步骤和订阅ID
+func splitSubscriptionState(state string) (step, id string) {
+    for _, step := range subscriptionSteps {
+        if strings.HasPrefix(state, step+"_") {
+            return step, strings.TrimPrefix(state, step+"_")
+        }
+    }
+    return "", ""
+}
+
+// resolveSubscription 按订阅ID或列表序号查找订阅，返回订阅ID，找不到时返回空字符串
+func (b *Bot) resolveSubscription(input string) string {
+    input = strings.TrimSpace(input)
+    if b.config.FindRSS(input) >= 0 {
+        return input
+    }
+    if index, err := strconv.Atoi(input); err == nil && index >= 1 && index <= len(b.config.RSS) {
+        return b.config.RSS[index-1].ID
+    }
+    return ""
+}
+
+// adminIDs 返回管理员用户ID列表，未配置管理员时返回所有用户
+func (b *Bot) adminIDs() []int64 {
+    if len(b.config.Telegram.AdminUsers) == 0 {
+        return b.users
+    }
+    adminIDs := make([]int64, 0, len(b.config.Telegram.AdminUsers))
+    for _, adminStr := range b.config.Telegram.AdminUsers {
+        adminID, err := strconv.ParseInt(strings.TrimSpace(adminStr), 10, 64)
+        if err != nil {
+            log.Printf("无效的管理员ID: %s", adminStr)
+            continue
+        }
+        adminIDs = append(adminIDs, adminID)
+    }
+    return adminIDs
+}
+
 // 添加 contains 辅助函数
 func contains(slice []int64, item int64) bool {
     for _, v := range slice {
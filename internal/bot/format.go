@@ -0,0 +1,85 @@
+package bot
+
+import (
+    "log"
+    "strings"
+
+    "rss2tg/internal/config"
+    "rss2tg/internal/msgtemplate"
+)
+
+// messageFormat 一种推送消息格式：发送时使用的 parse_mode，以及生成消息时的转义和标记方式
+type messageFormat struct {
+    name      string             // 配置中的格式名称
+    parseMode string             // Telegram 的 parse_mode，纯文本为空
+    markup    msgtemplate.Markup // 转义和标记方式
+}
+
+var (
+    markdownV2Format = &messageFormat{name: config.ParseModeMarkdownV2, parseMode: "MarkdownV2", markup: markdownV2Markup{}}
+    htmlFormat       = &messageFormat{name: config.ParseModeHTML, parseMode: "HTML", markup: htmlMarkup{}}
+    plainFormat      = &messageFormat{name: config.ParseModePlain, markup: msgtemplate.Plain}
+)
+
+// formatFor 返回推送目标（用户ID或频道）使用的消息格式，调用方需持有配置锁
+func (b *Bot) formatFor(target string) *messageFormat {
+    switch b.config.ParseModeFor(target) {
+    case config.ParseModeHTML:
+        return htmlFormat
+    case config.ParseModePlain:
+        return plainFormat
+    default:
+        return markdownV2Format
+    }
+}
+
+// deliver 按指定格式生成并发送消息，Telegram 无法解析消息格式时改为纯文本重新发送，避免丢失文章
+func (b *Bot) deliver(out *outgoing, format *messageFormat, send func(text, parseMode string) error) error {
+    err := send(b.renderMessage(out, format), format.parseMode)
+    if err != nil && format.parseMode != "" && isParseError(err) {
+        log.Printf("⚠️ Telegram 无法解析 %s 格式的消息，改为纯文本发送: %v", format.name, err)
+        err = send(b.renderMessage(out, plainFormat), "")
+    }
+    return err
+}
+
+// isParseError 判断发送失败是否因为消息格式错误，如转义遗漏或标记不完整
+func isParseError(err error) bool {
+    return strings.Contains(strings.ToLower(err.Error()), "can't parse entities")
+}
+
+// markdownV2Markup MarkdownV2 格式，所有特殊字符都需要转义
+type markdownV2Markup struct{}
+
+func (markdownV2Markup) Escape(text string) string { return escapeMarkdownV2Text(text) }
+func (markdownV2Markup) Bold(text string) string   { return "*" + escapeMarkdownV2Text(text) + "*" }
+func (markdownV2Markup) Italic(text string) string { return "_" + escapeMarkdownV2Text(text) + "_" }
+
+// Code 等宽文本中只需转义 ` 和 \
+func (markdownV2Markup) Code(text string) string {
+    return "`" + markdownV2CodeEscaper.Replace(text) + "`"
+}
+
+// Link 链接地址中只需转义 ) 和 \
+func (markdownV2Markup) Link(text, url string) string {
+    return "[" + escapeMarkdownV2Text(text) + "](" + markdownV2URLEscaper.Replace(url) + ")"
+}
+
+var (
+    markdownV2CodeEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+    markdownV2URLEscaper  = strings.NewReplacer("\\", "\\\\", ")", "\\)")
+)
+
+// htmlMarkup HTML 格式，只需转义 <、>、& 和引号
+type htmlMarkup struct{}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+
+func (htmlMarkup) Escape(text string) string { return htmlEscaper.Replace(text) }
+func (htmlMarkup) Bold(text string) string   { return "<b>" + htmlEscaper.Replace(text) + "</b>" }
+func (htmlMarkup) Italic(text string) string { return "<i>" + htmlEscaper.Replace(text) + "</i>" }
+func (htmlMarkup) Code(text string) string   { return "<code>" + htmlEscaper.Replace(text) + "</code>" }
+
+func (htmlMarkup) Link(text, url string) string {
+    return "<a href=\"" + htmlEscaper.Replace(url) + "\">" + htmlEscaper.Replace(text) + "</a>"
+}
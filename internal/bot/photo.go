@@ -0,0 +1,169 @@
+package bot
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "path"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+    "rss2tg/internal/model"
+)
+
+const (
+    captionLimit  = 1024             // 图片说明的最大长度（字符）
+    maxImages     = 10               // 媒体组最多包含的图片数
+    maxImageBytes = 10 * 1024 * 1024 // Telegram 上传图片的大小限制
+)
+
+// photo 已下载的图片
+type photo struct {
+    name string
+    data []byte
+}
+
+var imageClient = &http.Client{Timeout: 20 * time.Second}
+
+// sendsImages 判断订阅的文章是否以图片加说明的形式发送，调用方需持有配置锁
+func (b *Bot) sendsImages(id string) bool {
+    for i := range b.config.RSS {
+        if b.config.RSS[i].ID == id {
+            return b.config.SendsImages(&b.config.RSS[i])
+        }
+    }
+    return b.config.Telegram.SendImages
+}
+
+// fetchImages 下载文章的图片，供所有推送目标共用。文章没有图片或全部下载失败时返回 nil，
+// 此时发送文字消息
+func fetchImages(item *model.Item) []photo {
+    urls := item.Images()
+    if len(urls) == 0 {
+        return nil
+    }
+    if len(urls) > maxImages {
+        urls = urls[:maxImages]
+    }
+
+    photos := make([]photo, 0, len(urls))
+    for _, url := range urls {
+        p, err := downloadImage(url)
+        if err != nil {
+            log.Printf("下载图片 %s 失败: %v", url, err)
+            continue
+        }
+        photos = append(photos, p)
+    }
+    if len(photos) == 0 {
+        log.Printf("订阅 [%s] 的文章图片全部下载失败，改为发送文字消息", item.SubscriptionID)
+        return nil
+    }
+    return photos
+}
+
+// downloadImage 下载图片，检查大小和内容类型
+func downloadImage(url string) (photo, error) {
+    resp, err := imageClient.Get(url)
+    if err != nil {
+        return photo{}, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return photo{}, fmt.Errorf("HTTP状态码 %d", resp.StatusCode)
+    }
+    data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+    if err != nil {
+        return photo{}, err
+    }
+    if len(data) > maxImageBytes {
+        return photo{}, fmt.Errorf("图片超过 %dMB", maxImageBytes/1024/1024)
+    }
+    if contentType := http.DetectContentType(data); !strings.HasPrefix(contentType, "image/") {
+        return photo{}, fmt.Errorf("不是图片: %s", contentType)
+    }
+
+    name := path.Base(strings.SplitN(url, "?", 2)[0])
+    if name == "" || name == "." || name == "/" {
+        name = "image.jpg"
+    }
+    return photo{name: name, data: data}, nil
+}
+
+// deliverItem 发送文章：有图片时以图片加说明的形式发送，说明超过长度限制时图片不带说明，
+// 文字另发一条消息；图片发送失败时改为发送文字消息
+func (b *Bot) deliverItem(out *outgoing, format *messageFormat, chat string, threadID int, sendText func(text, parseMode string) error) error {
+    photos := out.photos
+    if len(photos) == 0 {
+        return b.deliver(out, format, sendText)
+    }
+
+    caption, parseMode := b.renderMessage(out, format), format.parseMode
+    plain := b.renderMessage(out, plainFormat)
+    long := utf8.RuneCountInString(plain) > captionLimit
+    if long {
+        caption, parseMode = "", ""
+    }
+
+    err := b.sendPhotos(chat, threadID, photos, caption, parseMode)
+    if err != nil && parseMode != "" && isParseError(err) {
+        log.Printf("⚠️ Telegram 无法解析 %s 格式的图片说明，改为纯文本发送: %v", format.name, err)
+        err = b.sendPhotos(chat, threadID, photos, plain, "")
+    }
+    if err != nil {
+        log.Printf("发送图片到 %s 失败，改为发送文字消息: %v", chat, err)
+        return b.deliver(out, format, sendText)
+    }
+    if long {
+        return b.deliver(out, format, sendText)
+    }
+    return nil
+}
+
+// sendPhotos 发送一张图片或一组图片，说明附在第一张图片上
+func (b *Bot) sendPhotos(chat string, threadID int, photos []photo, caption, parseMode string) error {
+    params := tgbotapi.Params{}
+    params["chat_id"] = chat
+    params.AddNonZero("message_thread_id", threadID)
+
+    if len(photos) == 1 {
+        params.AddNonEmpty("caption", caption)
+        params.AddNonEmpty("parse_mode", parseMode)
+        files := []tgbotapi.RequestFile{{
+            Name: "photo",
+            Data: tgbotapi.FileBytes{Name: photos[0].name, Bytes: photos[0].data},
+        }}
+        _, err := b.api.UploadFiles("sendPhoto", params, files)
+        return err
+    }
+
+    type inputMedia struct {
+        Type      string `json:"type"`
+        Media     string `json:"media"`
+        Caption   string `json:"caption,omitempty"`
+        ParseMode string `json:"parse_mode,omitempty"`
+    }
+    media := make([]inputMedia, len(photos))
+    files := make([]tgbotapi.RequestFile, len(photos))
+    for i, p := range photos {
+        name := "file-" + strconv.Itoa(i)
+        media[i] = inputMedia{Type: "photo", Media: "attach://" + name}
+        files[i] = tgbotapi.RequestFile{Name: name, Data: tgbotapi.FileBytes{Name: p.name, Bytes: p.data}}
+    }
+    media[0].Caption = caption
+    media[0].ParseMode = parseMode
+
+    data, err := json.Marshal(media)
+    if err != nil {
+        return err
+    }
+    params["media"] = string(data)
+    _, err = b.api.UploadFiles("sendMediaGroup", params, files)
+    return err
+}
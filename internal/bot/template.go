@@ -0,0 +1,112 @@
+package bot
+
+import (
+    "fmt"
+    "log"
+    "strconv"
+
+    tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+    "rss2tg/internal/model"
+    "rss2tg/internal/msgtemplate"
+)
+
+// outgoing 一篇待推送的文章，以及推送前在读锁下确定的消息模板和下载的图片
+type outgoing struct {
+    item     *model.Item
+    template string  // 订阅使用的消息模板，为空时使用内置格式
+    photos   []photo // 已下载的图片，为空时发送文字消息
+}
+
+// renderMessage 按订阅或全局的消息模板生成指定格式的消息，未设置模板或渲染失败时使用内置格式
+func (b *Bot) renderMessage(out *outgoing, format *messageFormat) string {
+    item := out.item
+    if out.template == "" {
+        return formatDefaultMessage(item, format.markup)
+    }
+    rendered, err := b.executeTemplate(out.template, item, format.markup)
+    if err != nil {
+        log.Printf("订阅 [%s] 的消息模板渲染失败，使用内置格式: %v", item.SubscriptionID, err)
+        return formatDefaultMessage(item, format.markup)
+    }
+    return rendered
+}
+
+// templateFor 返回订阅使用的消息模板，订阅不存在时使用全局模板，调用方需持有配置锁
+func (b *Bot) templateFor(id string) string {
+    for i := range b.config.RSS {
+        if b.config.RSS[i].ID == id {
+            return b.config.TemplateFor(&b.config.RSS[i])
+        }
+    }
+    return b.config.Telegram.Template
+}
+
+// executeTemplate 渲染模板，解析结果按模板内容缓存，配置修改后自动使用新模板
+func (b *Bot) executeTemplate(text string, item *model.Item, markup msgtemplate.Markup) (string, error) {
+    b.templateMu.Lock()
+    tmpl, ok := b.templates[text]
+    if !ok {
+        var err error
+        if tmpl, err = msgtemplate.Parse("template", text); err != nil {
+            b.templateMu.Unlock()
+            return "", err
+        }
+        if b.templates == nil {
+            b.templates = make(map[string]*msgtemplate.Template)
+        }
+        b.templates[text] = tmpl
+    }
+    b.templateMu.Unlock()
+
+    return tmpl.Execute(item, markup)
+}
+
+// handlePreview 使用示例文章渲染订阅或全局的消息模板，按实际推送的格式发送给用户
+func (b *Bot) handlePreview(chatID int64, userID int64, args string) {
+    if args == "" {
+        b.userState[userID] = "preview_target"
+        b.sendMessage(chatID, b.listSubscriptions()+"\n请输入要预览消息模板的订阅ID或编号，输入 0 预览全局模板：")
+        return
+    }
+
+    item := msgtemplate.SampleItem()
+    title := "全局模板"
+    text := b.config.Telegram.Template
+    if args != "0" {
+        id := b.resolveSubscription(args)
+        if id == "" {
+            b.sendMessage(chatID, fmt.Sprintf("无效的订阅ID或编号：%s", args))
+            return
+        }
+        for i := range b.config.RSS {
+            if b.config.RSS[i].ID == id {
+                item.SubscriptionID = id
+                item.Group = b.config.RSS[i].Group
+                text = b.config.TemplateFor(&b.config.RSS[i])
+                break
+            }
+        }
+        title = fmt.Sprintf("订阅 [%s] 的模板", id)
+    }
+
+    // 按当前对话作为推送目标时的消息格式预览
+    format := b.formatFor(strconv.FormatInt(chatID, 10))
+    var rendered string
+    if text == "" {
+        title += "（未设置，使用内置格式）"
+        rendered = formatDefaultMessage(item, format.markup)
+    } else {
+        var err error
+        if rendered, err = b.executeTemplate(text, item, format.markup); err != nil {
+            b.sendMessage(chatID, fmt.Sprintf("❌ 消息模板渲染失败：%v", err))
+            return
+        }
+    }
+
+    b.sendMessage(chatID, fmt.Sprintf("🖼 %s预览（%s 格式，使用示例文章）：", title, format.name))
+    msg := tgbotapi.NewMessage(chatID, rendered)
+    msg.ParseMode = format.parseMode
+    b.queueReply(msg, func(err error) {
+        b.sendText(chatID, fmt.Sprintf("⚠️ Telegram 无法解析渲染结果：%v\n请检查模板中的文章内容是否都经过 escape、bold、link 等函数输出，实际推送时将改为纯文本发送。渲染结果：\n%s", err, rendered))
+    })
+}
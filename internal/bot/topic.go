@@ -0,0 +1,197 @@
+package bot
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+    "sort"
+    "strconv"
+    "strings"
+
+    tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+    "rss2tg/internal/config"
+    "rss2tg/internal/model"
+)
+
+// sendToChannel 发送消息到频道推送目标。目标带话题ID时发送到该话题；
+// 目标是 telegram.forums 中的群组时按文章分组发送到对应话题，话题不存在时自动创建
+func (b *Bot) sendToChannel(channel string, item *model.Item, text, parseMode string) error {
+    chat, threadID, err := b.channelTarget(channel, item.Group)
+    if err != nil {
+        return err
+    }
+    if threadID == 0 {
+        msg := tgbotapi.NewMessageToChannel(chat, text)
+        msg.ParseMode = parseMode
+        _, err := b.api.Send(msg)
+        return err
+    }
+
+    err = b.sendToTopic(chat, threadID, text, parseMode)
+    if err != nil && isThreadNotFound(err) && b.forgetGroupTopic(chat, item.Group, threadID) {
+        // 分组对应的话题已被删除，重新创建后再发送一次
+        log.Printf("群组 %s 中分组 %s 的话题 %d 已不存在，重新创建", chat, item.Group, threadID)
+        if threadID = b.groupTopic(chat, item.Group); threadID != 0 {
+            err = b.sendToTopic(chat, threadID, text, parseMode)
+        }
+    }
+    return err
+}
+
+// channelTarget 解析频道推送目标，返回群组或频道以及文章分组对应的话题ID，没有话题时为0
+func (b *Bot) channelTarget(channel, group string) (string, int, error) {
+    chat, threadID, err := config.ParseChannelTarget(channel)
+    if err != nil {
+        return "", 0, err
+    }
+    if threadID == 0 {
+        threadID = b.groupTopic(chat, group)
+    }
+    return chat, threadID, nil
+}
+
+// sendToTopic 发送消息到群组话题，当前版本的 tgbotapi 不支持 message_thread_id，直接调用接口
+func (b *Bot) sendToTopic(chat string, threadID int, text, parseMode string) error {
+    params := tgbotapi.Params{}
+    params["chat_id"] = chat
+    params["text"] = text
+    params.AddNonEmpty("parse_mode", parseMode)
+    params.AddNonZero("message_thread_id", threadID)
+    _, err := b.api.MakeRequest("sendMessage", params)
+    return err
+}
+
+// groupTopic 返回群组中文章分组对应的话题ID，群组未开启分组话题时返回0。
+// 话题不存在时创建并写回配置文件，创建失败时返回0，消息发送到群组的默认话题。
+// 在推送文章的协程中调用，读取配置时持有配置读锁，写回时持有写锁，创建话题期间不持有配置锁
+func (b *Bot) groupTopic(chat, group string) int {
+    b.topicMu.Lock()
+    defer b.topicMu.Unlock()
+
+    b.configMu.RLock()
+    topics, ok := b.config.Telegram.Forums[chat]
+    threadID := topics[group]
+    b.configMu.RUnlock()
+    if !ok || group == "" {
+        return 0
+    }
+    if threadID > 0 {
+        return threadID
+    }
+
+    threadID, err := b.createForumTopic(chat, group)
+    if err != nil {
+        log.Printf("在群组 %s 中创建话题 %s 失败: %v", chat, group, err)
+        return 0
+    }
+    log.Printf("📌 在群组 %s 中创建了话题 %s (ID: %d)", chat, group, threadID)
+
+    b.configMu.Lock()
+    defer b.configMu.Unlock()
+
+    // 创建话题期间配置可能已被重新加载，写入当前的配置
+    if _, ok := b.config.Telegram.Forums[chat]; !ok {
+        return threadID
+    }
+    if b.config.Telegram.Forums[chat] == nil {
+        b.config.Telegram.Forums[chat] = make(map[string]int)
+    }
+    b.config.Telegram.Forums[chat][group] = threadID
+    if err := b.saveGroupTopic(chat, group, threadID); err != nil {
+        log.Printf("保存话题ID失败: %v", err)
+    }
+    return threadID
+}
+
+// saveGroupTopic 只把话题ID写入配置文件：重新读取配置文件，合并话题记录后保存，
+// 避免把内存中尚未完成的添加或编辑订阅写入文件。调用方需持有配置写锁
+func (b *Bot) saveGroupTopic(chat, group string, threadID int) error {
+    cfg, err := config.Load(b.configFile)
+    if err != nil {
+        return err
+    }
+    topics, ok := cfg.Telegram.Forums[chat]
+    if !ok {
+        // 配置文件中已不再为该群组开启话题
+        return nil
+    }
+    if topics == nil {
+        topics = make(map[string]int)
+        cfg.Telegram.Forums[chat] = topics
+    }
+    topics[group] = threadID
+    return cfg.Save(b.configFile)
+}
+
+// forgetGroupTopic 删除失效的分组话题记录，记录已被其他消息更新时返回 false
+func (b *Bot) forgetGroupTopic(chat, group string, threadID int) bool {
+    b.topicMu.Lock()
+    defer b.topicMu.Unlock()
+    b.configMu.Lock()
+    defer b.configMu.Unlock()
+
+    topics := b.config.Telegram.Forums[chat]
+    if topics == nil || topics[group] != threadID {
+        return false
+    }
+    delete(topics, group)
+    return true
+}
+
+// createForumTopic 在群组中创建以分组名称命名的话题，返回话题ID
+func (b *Bot) createForumTopic(chat, name string) (int, error) {
+    // 话题名称最长128个字符
+    if runes := []rune(name); len(runes) > 128 {
+        name = string(runes[:128])
+    }
+    params := tgbotapi.Params{}
+    params["chat_id"] = chat
+    params["name"] = name
+    resp, err := b.api.MakeRequest("createForumTopic", params)
+    if err != nil {
+        return 0, err
+    }
+
+    var topic struct {
+        MessageThreadID int `json:"message_thread_id"`
+    }
+    if err := json.Unmarshal(resp.Result, &topic); err != nil {
+        return 0, fmt.Errorf("解析话题信息失败: %v", err)
+    }
+    if topic.MessageThreadID == 0 {
+        return 0, fmt.Errorf("返回的话题ID为空")
+    }
+    return topic.MessageThreadID, nil
+}
+
+// isThreadNotFound 判断发送失败是否因为话题不存在
+func isThreadNotFound(err error) bool {
+    return strings.Contains(strings.ToLower(err.Error()), "thread not found")
+}
+
+// formatForums 格式化分组话题配置，用于配置信息展示
+func formatForums(forums map[string]map[string]int) []string {
+    chats := make([]string, 0, len(forums))
+    for chat := range forums {
+        chats = append(chats, chat)
+    }
+    sort.Strings(chats)
+
+    lines := make([]string, 0, len(chats))
+    for _, chat := range chats {
+        groups := make([]string, 0, len(forums[chat]))
+        for group := range forums[chat] {
+            groups = append(groups, group)
+        }
+        sort.Strings(groups)
+        mapping := make([]string, len(groups))
+        for i, group := range groups {
+            mapping[i] = group + " → " + strconv.Itoa(forums[chat][group])
+        }
+        if len(mapping) == 0 {
+            mapping = append(mapping, "暂无")
+        }
+        lines = append(lines, fmt.Sprintf("%s: %s", chat, strings.Join(mapping, ", ")))
+    }
+    return lines
+}
@@ -1,16 +1,21 @@
 package config
 
 import (
+    "crypto/sha1"
     "fmt"
     "io/ioutil"
     "log"
     "net/url"
     "os"
+    "sort"
     "strconv"
     "strings"
     "path/filepath"
 
     "gopkg.in/yaml.v2"
+    "rss2tg/internal/filter"
+    "rss2tg/internal/model"
+    "rss2tg/internal/msgtemplate"
 )
 
 // Config 定义了整个应用的配置结构
@@ -20,6 +25,11 @@ type Config struct {
         Users       []string `yaml:"users"`
         Channels    []string `yaml:"channels"`
         AdminUsers  []string `yaml:"adminuser,omitempty"`  // 管理员用户ID列表
+        Forums      map[string]map[string]int `yaml:"forums,omitempty"` // 开启话题的群组：群组ID -> 分组名称 -> 话题ID，缺少的话题由机器人自动创建
+        Template    string   `yaml:"template,omitempty"`   // 消息模板（Go text/template），为空时使用内置格式
+        ParseMode   string   `yaml:"parse_mode,omitempty"` // 消息格式：markdownv2（默认）、html 或 plain
+        ParseModes  map[string]string `yaml:"parse_modes,omitempty"` // 按推送目标（用户ID或频道）设置的消息格式
+        SendImages  bool     `yaml:"send_images,omitempty"` // 文章有图片时以图片加说明的形式发送
     } `yaml:"telegram"`
     Webhook struct {
         Enabled    bool   `yaml:"enabled"`      // 是否启用 webhook 推送（向后兼容）
@@ -28,18 +38,71 @@ type Config struct {
         RetryCount int    `yaml:"retry_count"`  // 失败重试次数（向后兼容）
     } `yaml:"webhook"`
     Webhooks []WebhookEntry `yaml:"webhooks,omitempty"` // 多个 webhook 配置
+    Health struct {
+        AlertAfterHours int `yaml:"alert_after_hours,omitempty"` // 订阅连续失败多少小时后提醒管理员
+        MaxBackoff      int `yaml:"max_backoff,omitempty"`       // 失败退避的最大间隔（秒）
+    } `yaml:"health,omitempty"`
+    Dedup struct {
+        CrossFeed   bool    `yaml:"cross_feed,omitempty"`   // 是否抑制不同订阅中的重复文章
+        WindowHours int     `yaml:"window_hours,omitempty"` // 重复检测窗口（小时），默认24
+        Similarity  float64 `yaml:"similarity,omitempty"`   // 标题相似度阈值（0-1），默认0.85
+    } `yaml:"dedup,omitempty"`
+    Scheduler struct {
+        MaxConcurrency     int `yaml:"max_concurrency,omitempty"`      // 全局最大并发抓取数，默认8
+        PerHostConcurrency int `yaml:"per_host_concurrency,omitempty"` // 同一主机最大并发抓取数，默认2
+        StartJitter        int `yaml:"start_jitter,omitempty"`         // 订阅开始调度时的随机延迟上限（秒），默认30
+    } `yaml:"scheduler,omitempty"`
+    Blocklist []string `yaml:"blocklist,omitempty"` // 全局屏蔽词，对所有订阅生效，命中的文章不推送
+    KeywordSets map[string][]string `yaml:"keyword_sets,omitempty"` // 命名的关键词组，订阅通过 keyword_sets 按名称引用
+    GroupRoutes map[string]Route `yaml:"group_routes,omitempty"` // 按分组设置的推送目标，订阅未设置 route 时使用
+    Normalize struct {
+        Synonyms                map[string]string `yaml:"synonyms,omitempty"`                  // 同义词表：别名 -> 标准词，与默认同义词合并，标准词为空时取消默认同义词
+        FoldWidth               bool              `yaml:"fold_width,omitempty"`                // 是否将全角字符转换为半角
+        TraditionalToSimplified bool              `yaml:"traditional_to_simplified,omitempty"` // 是否将繁体字转换为简体字
+    } `yaml:"normalize,omitempty"`
     RSS []RSSEntry `yaml:"rss"`
 }
 
 // RSSEntry 定义RSS配置项
 type RSSEntry struct {
-    URLs           []string `yaml:"urls,omitempty"`     // 新版本：支持多个URL
-    URL            string   `yaml:"url,omitempty"`      // 旧版本：单个URL
-    Interval       int      `yaml:"interval"`           // 更新间隔（秒）
-    Keywords       []string `yaml:"keywords"`           // 关键词列表
-    Group          string   `yaml:"group"`              // 分组名称
-    AllowPartMatch bool     `yaml:"allow_part_match"`   // 是否允许部分匹配
-    Enabled        bool     `yaml:"enabled"`            // 是否启用此订阅
+    ID                string      `yaml:"id,omitempty"`                   // 订阅的稳定短ID，加载时自动生成
+    URLs              []string    `yaml:"urls,omitempty"`                 // 新版本：支持多个URL
+    URL               string      `yaml:"url,omitempty"`                  // 旧版本：单个URL
+    Interval          int         `yaml:"interval"`                       // 更新间隔（秒）
+    Keywords          []string    `yaml:"keywords"`                       // 关键词列表
+    Group             string      `yaml:"group"`                          // 分组名称
+    AllowPartMatch    bool        `yaml:"allow_part_match"`               // 是否允许部分匹配
+    Enabled           bool        `yaml:"enabled"`                        // 是否启用此订阅
+    PrimeOnFirstFetch bool        `yaml:"prime_on_first_fetch,omitempty"` // 首次抓取时只标记已有文章，不推送
+    PrimeSendNewest   int         `yaml:"prime_send_newest,omitempty"`    // 首次抓取时仍推送的最新文章数
+    DedupKey          string      `yaml:"dedup_key,omitempty"`            // 去重方式：link、guid、normalized_link、title_date
+    StripParams       []string    `yaml:"strip_params,omitempty"`         // 规范化链接时额外去除的参数，支持 * 前缀匹配
+    MaxAgeHours       int         `yaml:"max_age_hours,omitempty"`        // 文章最大时效（小时），超过则不推送，0表示不限制
+    ExcludeKeywords   []string    `yaml:"exclude_keywords,omitempty"`     // 排除词，命中任意一条的文章不推送
+    Regex             []RegexRule `yaml:"regex,omitempty"`                // 命名的正则规则，命中时以规则名称展示
+    MatchFields       []string    `yaml:"match_fields,omitempty"`         // 关键词默认匹配的字段：title、description、content、author、category
+    StripPunctuation  *bool       `yaml:"strip_punctuation,omitempty"`    // 匹配前是否去除标点，未设置时默认去除
+    FuzzyMatch        bool        `yaml:"fuzzy_match,omitempty"`          // 精确匹配失败时按编辑距离模糊匹配关键词
+    MinScore          float64     `yaml:"min_score,omitempty"`            // 分数阈值，总分超过该值才推送，未设置时总分大于0即推送
+    KeywordSets       []string    `yaml:"keyword_sets,omitempty"`         // 引用的关键词组名称，与 keywords 一起参与匹配
+    Route             Route       `yaml:"route,omitempty"`                // 推送目标，未设置时使用分组或全局的设置
+    Template          string      `yaml:"template,omitempty"`             // 消息模板，未设置时使用全局模板
+    SendImages        *bool       `yaml:"send_images,omitempty"`          // 是否以图片加说明的形式发送，未设置时使用全局设置
+}
+
+// Route 定义推送目标。用户和频道作为 Telegram 目标一起生效，webhook 单独生效，
+// 未设置的部分依次使用分组和全局的设置
+type Route struct {
+    Users    []string `yaml:"users,omitempty"`    // 接收推送的用户ID
+    Channels []string `yaml:"channels,omitempty"` // 接收推送的频道
+    Webhooks []string `yaml:"webhooks,omitempty"` // 使用的 webhook 名称，对应 webhooks 中的 name
+}
+
+// RegexRule 定义一条命名的正则匹配规则
+type RegexRule struct {
+    Name    string  `yaml:"name"`             // 规则名称，用于消息中展示
+    Pattern string  `yaml:"pattern"`          // 正则表达式，默认不区分大小写
+    Weight  float64 `yaml:"weight,omitempty"` // 命中时计入的分数，可以为负数，未设置时为1
 }
 
 // WebhookEntry 定义单个 webhook 配置项
@@ -55,13 +118,29 @@ type WebhookEntry struct {
 func (r *RSSEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
     // 定义一个临时结构体来解析YAML
     type tempRSSEntry struct {
-        URLs           []string `yaml:"urls,omitempty"`
-        URL            string   `yaml:"url,omitempty"`
-        Interval       int      `yaml:"interval"`
-        Keywords       []string `yaml:"keywords"`
-        Group          string   `yaml:"group"`
-        AllowPartMatch *bool    `yaml:"allow_part_match,omitempty"`  // 使用指针类型
-        Enabled        *bool    `yaml:"enabled,omitempty"`          // 使用指针类型
+        ID                string      `yaml:"id,omitempty"`
+        URLs              []string    `yaml:"urls,omitempty"`
+        URL               string      `yaml:"url,omitempty"`
+        Interval          int         `yaml:"interval"`
+        Keywords          []string    `yaml:"keywords"`
+        Group             string      `yaml:"group"`
+        AllowPartMatch    *bool       `yaml:"allow_part_match,omitempty"` // 使用指针类型
+        Enabled           *bool       `yaml:"enabled,omitempty"`          // 使用指针类型
+        PrimeOnFirstFetch bool        `yaml:"prime_on_first_fetch,omitempty"`
+        PrimeSendNewest   int         `yaml:"prime_send_newest,omitempty"`
+        DedupKey          string      `yaml:"dedup_key,omitempty"`
+        StripParams       []string    `yaml:"strip_params,omitempty"`
+        MaxAgeHours       int         `yaml:"max_age_hours,omitempty"`
+        ExcludeKeywords   []string    `yaml:"exclude_keywords,omitempty"`
+        Regex             []RegexRule `yaml:"regex,omitempty"`
+        MatchFields       []string    `yaml:"match_fields,omitempty"`
+        StripPunctuation  *bool       `yaml:"strip_punctuation,omitempty"`
+        FuzzyMatch        bool        `yaml:"fuzzy_match,omitempty"`
+        MinScore          float64     `yaml:"min_score,omitempty"`
+        KeywordSets       []string    `yaml:"keyword_sets,omitempty"`
+        Route             Route       `yaml:"route,omitempty"`
+        Template          string      `yaml:"template,omitempty"`
+        SendImages        *bool       `yaml:"send_images,omitempty"`
     }
 
     // 解析配置到临时结构体
@@ -71,11 +150,27 @@ func (r *RSSEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
     }
 
     // 复制字段到实际结构体
+    r.ID = temp.ID
     r.URLs = temp.URLs
     r.URL = temp.URL
     r.Interval = temp.Interval
     r.Keywords = temp.Keywords
     r.Group = temp.Group
+    r.PrimeOnFirstFetch = temp.PrimeOnFirstFetch
+    r.PrimeSendNewest = temp.PrimeSendNewest
+    r.DedupKey = temp.DedupKey
+    r.StripParams = temp.StripParams
+    r.MaxAgeHours = temp.MaxAgeHours
+    r.ExcludeKeywords = temp.ExcludeKeywords
+    r.Regex = temp.Regex
+    r.MatchFields = temp.MatchFields
+    r.StripPunctuation = temp.StripPunctuation
+    r.FuzzyMatch = temp.FuzzyMatch
+    r.MinScore = temp.MinScore
+    r.KeywordSets = temp.KeywordSets
+    r.Route = temp.Route
+    r.Template = temp.Template
+    r.SendImages = temp.SendImages
 
     // 如果存在旧版本的单个URL，将其转换为URLs数组
     if r.URL != "" {
@@ -111,6 +206,15 @@ func (c *Config) Equal(other *Config) bool {
     if !stringSliceEqual(c.Telegram.Channels, other.Telegram.Channels) {
         return false
     }
+    if !forumsEqual(c.Telegram.Forums, other.Telegram.Forums) {
+        return false
+    }
+    if c.Telegram.Template != other.Telegram.Template ||
+       c.Telegram.ParseMode != other.Telegram.ParseMode ||
+       c.Telegram.SendImages != other.Telegram.SendImages ||
+       !stringMapEqual(c.Telegram.ParseModes, other.Telegram.ParseModes) {
+        return false
+    }
     // 检查 webhook 配置
     if c.Webhook.Enabled != other.Webhook.Enabled ||
        c.Webhook.URL != other.Webhook.URL ||
@@ -131,16 +235,66 @@ func (c *Config) Equal(other *Config) bool {
             return false
         }
     }
+    // 检查健康检查配置
+    if c.Health != other.Health {
+        return false
+    }
+    // 检查去重配置
+    if c.Dedup != other.Dedup {
+        return false
+    }
+    // 检查调度配置
+    if c.Scheduler != other.Scheduler {
+        return false
+    }
+    if !stringSliceEqual(c.Blocklist, other.Blocklist) {
+        return false
+    }
+    if !keywordSetsEqual(c.KeywordSets, other.KeywordSets) {
+        return false
+    }
+    if len(c.GroupRoutes) != len(other.GroupRoutes) {
+        return false
+    }
+    for group, route := range c.GroupRoutes {
+        if otherRoute, ok := other.GroupRoutes[group]; !ok || !route.Equal(otherRoute) {
+            return false
+        }
+    }
+    // 检查标准化配置
+    if !stringMapEqual(c.Normalize.Synonyms, other.Normalize.Synonyms) ||
+       c.Normalize.FoldWidth != other.Normalize.FoldWidth ||
+       c.Normalize.TraditionalToSimplified != other.Normalize.TraditionalToSimplified {
+        return false
+    }
     if len(c.RSS) != len(other.RSS) {
         return false
     }
     for i := range c.RSS {
+        if c.RSS[i].ID != other.RSS[i].ID {
+            return false
+        }
         if !stringSliceEqual(c.RSS[i].URLs, other.RSS[i].URLs) {
             return false
         }
         if c.RSS[i].Interval != other.RSS[i].Interval ||
            c.RSS[i].Group != other.RSS[i].Group ||
-           !stringSliceEqual(c.RSS[i].Keywords, other.RSS[i].Keywords) {
+           c.RSS[i].PrimeOnFirstFetch != other.RSS[i].PrimeOnFirstFetch ||
+           c.RSS[i].PrimeSendNewest != other.RSS[i].PrimeSendNewest ||
+           c.RSS[i].DedupKey != other.RSS[i].DedupKey ||
+           c.RSS[i].MaxAgeHours != other.RSS[i].MaxAgeHours ||
+           !stringSliceEqual(c.RSS[i].StripParams, other.RSS[i].StripParams) ||
+           !stringSliceEqual(c.RSS[i].Keywords, other.RSS[i].Keywords) ||
+           !stringSliceEqual(c.RSS[i].ExcludeKeywords, other.RSS[i].ExcludeKeywords) ||
+           !regexRulesEqual(c.RSS[i].Regex, other.RSS[i].Regex) ||
+           !stringSliceEqual(c.RSS[i].MatchFields, other.RSS[i].MatchFields) ||
+           c.RSS[i].StripsPunctuation() != other.RSS[i].StripsPunctuation() ||
+           c.RSS[i].FuzzyMatch != other.RSS[i].FuzzyMatch ||
+           c.RSS[i].MinScore != other.RSS[i].MinScore ||
+           !stringSliceEqual(c.RSS[i].KeywordSets, other.RSS[i].KeywordSets) ||
+           !c.RSS[i].Route.Equal(other.RSS[i].Route) ||
+           c.RSS[i].Template != other.RSS[i].Template ||
+           c.SendsImages(&c.RSS[i]) != other.SendsImages(&other.RSS[i]) {
             return false
         }
     }
@@ -159,6 +313,61 @@ func stringSliceEqual(a, b []string) bool {
     return true
 }
 
+func stringMapEqual(a, b map[string]string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for k, v := range a {
+        if w, ok := b[k]; !ok || w != v {
+            return false
+        }
+    }
+    return true
+}
+
+func keywordSetsEqual(a, b map[string][]string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for name, keywords := range a {
+        other, ok := b[name]
+        if !ok || !stringSliceEqual(keywords, other) {
+            return false
+        }
+    }
+    return true
+}
+
+func forumsEqual(a, b map[string]map[string]int) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for chat, topics := range a {
+        other, ok := b[chat]
+        if !ok || len(topics) != len(other) {
+            return false
+        }
+        for group, threadID := range topics {
+            if id, ok := other[group]; !ok || id != threadID {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+func regexRulesEqual(a, b []RegexRule) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
 func Load(path string) (*Config, error) {
     log.Printf("正在加载配置文件: %s", path)
     
@@ -413,6 +622,12 @@ func Load(path string) (*Config, error) {
         }
     }
 
+    // 为缺少ID或ID重复的订阅生成ID
+    if config.EnsureSubscriptionIDs() {
+        log.Println("已为订阅生成ID")
+        configChanged = true
+    }
+
     // 如果配置有变化，保存到文件
     if configChanged {
         log.Println("从环境变量补充了配置信息，正在保存到配置文件")
@@ -439,6 +654,133 @@ func validateAndCleanConfig(config *Config) error {
         return fmt.Errorf("未设置用户列表")
     }
 
+    // 验证频道和话题
+    for _, channel := range config.Telegram.Channels {
+        if _, _, err := ParseChannelTarget(channel); err != nil {
+            return err
+        }
+    }
+    forums := make(map[string]map[string]int, len(config.Telegram.Forums))
+    for chat, topics := range config.Telegram.Forums {
+        chat = strings.TrimSpace(chat)
+        if chat == "" {
+            continue
+        }
+        if _, threadID, err := ParseChannelTarget(chat); err != nil || threadID != 0 {
+            return fmt.Errorf("telegram.forums: 群组 %q 无效，应为群组ID", chat)
+        }
+        clean := make(map[string]int, len(topics))
+        for group, threadID := range topics {
+            if threadID <= 0 {
+                return fmt.Errorf("telegram.forums: 群组 %s 中分组 %q 的话题ID无效", chat, group)
+            }
+            clean[group] = threadID
+        }
+        forums[chat] = clean
+    }
+    if len(forums) == 0 {
+        forums = nil
+    }
+    config.Telegram.Forums = forums
+
+    // 验证消息格式
+    mode, err := cleanParseMode(config.Telegram.ParseMode)
+    if err != nil {
+        return fmt.Errorf("telegram.parse_mode: %v", err)
+    }
+    config.Telegram.ParseMode = mode
+    parseModes := make(map[string]string, len(config.Telegram.ParseModes))
+    for target, mode := range config.Telegram.ParseModes {
+        target = strings.TrimSpace(target)
+        if target == "" {
+            continue
+        }
+        if mode, err = cleanParseMode(mode); err != nil {
+            return fmt.Errorf("telegram.parse_modes: 目标 %s 的%v", target, err)
+        }
+        parseModes[target] = mode
+    }
+    if len(parseModes) == 0 {
+        parseModes = nil
+    }
+    config.Telegram.ParseModes = parseModes
+
+    // 验证消息模板
+    if strings.TrimSpace(config.Telegram.Template) != "" {
+        if _, err := msgtemplate.Parse("telegram.template", config.Telegram.Template); err != nil {
+            return fmt.Errorf("telegram.template 消息模板无效: %v", err)
+        }
+    }
+
+    // 清理全局屏蔽词
+    blocklist, err := cleanKeywords(config.Blocklist)
+    if err != nil {
+        return fmt.Errorf("blocklist 屏蔽词无效: %v", err)
+    }
+    if len(blocklist) == 0 {
+        blocklist = nil
+    }
+    config.Blocklist = blocklist
+
+    // 清理关键词组
+    keywordSets := make(map[string][]string, len(config.KeywordSets))
+    for name, keywords := range config.KeywordSets {
+        name = strings.TrimSpace(name)
+        if err := ValidateKeywordSetName(name); err != nil {
+            return err
+        }
+        clean, err := cleanKeywords(keywords)
+        if err != nil {
+            return fmt.Errorf("关键词组 %q 中的关键词无效: %v", name, err)
+        }
+        keywordSets[name] = clean
+    }
+    if len(keywordSets) == 0 {
+        keywordSets = nil
+    }
+    config.KeywordSets = keywordSets
+
+    // 清理同义词表
+    synonyms := make(map[string]string, len(config.Normalize.Synonyms))
+    for alias, canonical := range config.Normalize.Synonyms {
+        alias = strings.TrimSpace(alias)
+        canonical = strings.TrimSpace(canonical)
+        if alias == "" {
+            continue
+        }
+        if canonical == "" && !isDefaultSynonym(alias) {
+            return fmt.Errorf("normalize.synonyms: 别名 %q 的标准词为空", alias)
+        }
+        synonyms[alias] = canonical
+    }
+    if len(synonyms) == 0 {
+        synonyms = nil
+    }
+    config.Normalize.Synonyms = synonyms
+
+    // 验证分组推送目标
+    groupRoutes := make(map[string]Route, len(config.GroupRoutes))
+    for group, route := range config.GroupRoutes {
+        group = strings.TrimSpace(group)
+        if group == "" {
+            continue
+        }
+        clean, err := cleanRoute(route, config)
+        if err != nil {
+            return fmt.Errorf("group_routes: 分组 %q 的推送目标无效: %v", group, err)
+        }
+        groupRoutes[group] = clean
+    }
+    if len(groupRoutes) == 0 {
+        groupRoutes = nil
+    }
+    config.GroupRoutes = groupRoutes
+
+    // 验证去重配置
+    if config.Dedup.Similarity < 0 || config.Dedup.Similarity > 1 {
+        return fmt.Errorf("dedup.similarity 必须在 0 到 1 之间")
+    }
+
     // 验证和清理RSS配置
     for i := range config.RSS {
         // 验证URLs
@@ -459,7 +801,10 @@ func validateAndCleanConfig(config *Config) error {
             config.RSS[i].URLs[j] = urlStr // 保存清理后的URL
         }
 
-        // 设置默认间隔时间
+        // 设置默认间隔时间，未配置时为0
+        if config.RSS[i].Interval < 0 {
+            log.Printf("警告：RSS #%d 的 interval %d 无效，使用默认值300秒", i+1, config.RSS[i].Interval)
+        }
         if config.RSS[i].Interval <= 0 {
             config.RSS[i].Interval = 300 // 默认5分钟
         }
@@ -469,20 +814,393 @@ func validateAndCleanConfig(config *Config) error {
             config.RSS[i].Group = "默认分组"
         }
 
-        // 清理关键词列表
-        cleanKeywords := make([]string, 0)
-        for _, keyword := range config.RSS[i].Keywords {
-            keyword = strings.TrimSpace(keyword)
-            if keyword != "" {
-                cleanKeywords = append(cleanKeywords, keyword)
+        if config.RSS[i].PrimeSendNewest < 0 {
+            return fmt.Errorf("RSS #%d: prime_send_newest 不能为负数", i+1)
+        }
+
+        if config.RSS[i].MaxAgeHours < 0 {
+            return fmt.Errorf("RSS #%d: max_age_hours 不能为负数", i+1)
+        }
+
+        if config.RSS[i].MinScore < 0 {
+            return fmt.Errorf("RSS #%d: min_score 不能为负数", i+1)
+        }
+
+        // 验证去重方式
+        switch config.RSS[i].DedupKey {
+        case "", "link", "guid", "normalized_link", "title_date":
+        default:
+            return fmt.Errorf("RSS #%d: 无效的去重方式 %q，可选值: link、guid、normalized_link、title_date", i+1, config.RSS[i].DedupKey)
+        }
+
+        // 清理关键词和排除词列表
+        keywords, err := cleanKeywords(config.RSS[i].Keywords)
+        if err != nil {
+            return fmt.Errorf("RSS #%d: 关键词无效: %v", i+1, err)
+        }
+        config.RSS[i].Keywords = keywords
+
+        excludeKeywords, err := cleanKeywords(config.RSS[i].ExcludeKeywords)
+        if err != nil {
+            return fmt.Errorf("RSS #%d: 排除词无效: %v", i+1, err)
+        }
+        if len(excludeKeywords) == 0 {
+            excludeKeywords = nil
+        }
+        config.RSS[i].ExcludeKeywords = excludeKeywords
+
+        // 验证引用的关键词组
+        sets := make([]string, 0, len(config.RSS[i].KeywordSets))
+        for _, name := range config.RSS[i].KeywordSets {
+            name = strings.TrimSpace(name)
+            if name == "" {
+                continue
+            }
+            if _, ok := config.KeywordSets[name]; !ok {
+                return fmt.Errorf("RSS #%d: 引用的关键词组 %q 不存在", i+1, name)
+            }
+            sets = append(sets, name)
+        }
+        if len(sets) == 0 {
+            sets = nil
+        }
+        config.RSS[i].KeywordSets = sets
+
+        // 验证消息模板
+        if strings.TrimSpace(config.RSS[i].Template) != "" {
+            if _, err := msgtemplate.Parse("template", config.RSS[i].Template); err != nil {
+                return fmt.Errorf("RSS #%d: 消息模板无效: %v", i+1, err)
+            }
+        }
+
+        // 验证推送目标
+        route, err := cleanRoute(config.RSS[i].Route, config)
+        if err != nil {
+            return fmt.Errorf("RSS #%d: 推送目标无效: %v", i+1, err)
+        }
+        config.RSS[i].Route = route
+
+        // 验证匹配字段
+        for j, name := range config.RSS[i].MatchFields {
+            field, ok := filter.NormalizeField(name)
+            if !ok {
+                return fmt.Errorf("RSS #%d: 不支持的匹配字段 %q，可选值: title、description、content、author、category", i+1, name)
+            }
+            config.RSS[i].MatchFields[j] = field
+        }
+
+        // 验证正则规则，未设置名称时使用正则表达式本身
+        for j := range config.RSS[i].Regex {
+            rule := &config.RSS[i].Regex[j]
+            rule.Name = strings.TrimSpace(rule.Name)
+            if rule.Name == "" {
+                rule.Name = rule.Pattern
+            }
+            if _, err := filter.CompilePattern(rule.Pattern); err != nil {
+                return fmt.Errorf("RSS #%d: 正则规则 %q 无效: %v", i+1, rule.Name, err)
             }
         }
-        config.RSS[i].Keywords = cleanKeywords
     }
 
     return nil
 }
 
+// ValidateKeywordSetName 检查关键词组名称，名称不能为空或包含空白
+func ValidateKeywordSetName(name string) error {
+    if name == "" {
+        return fmt.Errorf("关键词组名称不能为空")
+    }
+    if strings.ContainsAny(name, " \t\r\n") {
+        return fmt.Errorf("关键词组名称 %q 不能包含空白", name)
+    }
+    return nil
+}
+
+// KeywordSetNames 返回按名称排序的关键词组名称
+func (c *Config) KeywordSetNames() []string {
+    names := make([]string, 0, len(c.KeywordSets))
+    for name := range c.KeywordSets {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// RemoveKeywordSet 删除关键词组，并移除所有订阅对它的引用
+func (c *Config) RemoveKeywordSet(name string) {
+    delete(c.KeywordSets, name)
+    for i := range c.RSS {
+        sets := make([]string, 0, len(c.RSS[i].KeywordSets))
+        for _, set := range c.RSS[i].KeywordSets {
+            if set != name {
+                sets = append(sets, set)
+            }
+        }
+        if len(sets) == 0 {
+            sets = nil
+        }
+        c.RSS[i].KeywordSets = sets
+    }
+}
+
+// RouteFor 返回订阅实际使用的推送目标。Telegram 目标（用户和频道）与 webhook 分别确定：
+// 订阅设置了则使用订阅的，否则使用所在分组的，都未设置时为空，表示使用全局设置
+func (c *Config) RouteFor(entry *RSSEntry) Route {
+    var route Route
+    group := c.GroupRoutes[entry.Group]
+
+    switch {
+    case entry.Route.HasTelegram():
+        route.Users, route.Channels = entry.Route.Users, entry.Route.Channels
+    case group.HasTelegram():
+        route.Users, route.Channels = group.Users, group.Channels
+    }
+
+    switch {
+    case len(entry.Route.Webhooks) > 0:
+        route.Webhooks = entry.Route.Webhooks
+    case len(group.Webhooks) > 0:
+        route.Webhooks = group.Webhooks
+    }
+    return route
+}
+
+// HasTelegram 判断是否设置了 Telegram 推送目标
+func (r Route) HasTelegram() bool {
+    return len(r.Users) > 0 || len(r.Channels) > 0
+}
+
+// IsEmpty 判断是否未设置任何推送目标
+func (r Route) IsEmpty() bool {
+    return !r.HasTelegram() && len(r.Webhooks) == 0
+}
+
+// Equal 比较两个推送目标是否相同
+func (r Route) Equal(other Route) bool {
+    return stringSliceEqual(r.Users, other.Users) &&
+        stringSliceEqual(r.Channels, other.Channels) &&
+        stringSliceEqual(r.Webhooks, other.Webhooks)
+}
+
+// cleanRoute 去除空白和空项，检查用户ID格式以及引用的 webhook 是否存在
+func cleanRoute(route Route, config *Config) (Route, error) {
+    var clean Route
+    for _, user := range route.Users {
+        user = strings.TrimSpace(user)
+        if user == "" {
+            continue
+        }
+        if _, err := strconv.ParseInt(user, 10, 64); err != nil {
+            return Route{}, fmt.Errorf("用户ID %q 无效", user)
+        }
+        clean.Users = append(clean.Users, user)
+    }
+    for _, channel := range route.Channels {
+        channel = strings.TrimSpace(channel)
+        if channel == "" {
+            continue
+        }
+        if _, _, err := ParseChannelTarget(channel); err != nil {
+            return Route{}, err
+        }
+        clean.Channels = append(clean.Channels, channel)
+    }
+    for _, name := range route.Webhooks {
+        name = strings.TrimSpace(name)
+        if name == "" {
+            continue
+        }
+        if !config.hasWebhook(name) {
+            return Route{}, fmt.Errorf("webhook %q 不存在，只能引用 webhooks 中配置的名称，未配置 webhooks 时可用 %q 引用单个 webhook 配置", name, model.LegacyWebhook)
+        }
+        clean.Webhooks = append(clean.Webhooks, name)
+    }
+    return clean, nil
+}
+
+// hasWebhook 检查推送目标引用的 webhook 名称是否存在，未配置 webhooks 数组时
+// 单个 webhook 配置（向后兼容）以 model.LegacyWebhook 为名称
+func (c *Config) hasWebhook(name string) bool {
+    for _, webhook := range c.Webhooks {
+        if webhook.Name == name {
+            return true
+        }
+    }
+    return len(c.Webhooks) == 0 && c.Webhook.URL != "" && name == model.LegacyWebhook
+}
+
+// TemplateFor 返回订阅使用的消息模板，订阅未设置时使用全局模板，都为空时使用内置格式
+func (c *Config) TemplateFor(entry *RSSEntry) string {
+    if strings.TrimSpace(entry.Template) != "" {
+        return entry.Template
+    }
+    return c.Telegram.Template
+}
+
+// SendsImages 返回订阅的文章是否以图片加说明的形式发送，订阅未设置时使用全局设置
+func (c *Config) SendsImages(entry *RSSEntry) bool {
+    if entry.SendImages != nil {
+        return *entry.SendImages
+    }
+    return c.Telegram.SendImages
+}
+
+// DefaultSynonyms 默认的同义词，将含符号的技术名词替换为去除标点后仍能区分的写法。
+// normalize.synonyms 中可以为这些别名指定其他标准词，标准词留空则取消该默认同义词
+var DefaultSynonyms = map[string]string{
+    "c++":  "cpp",
+    "c#":   "csharp",
+    ".net": "dotnet",
+}
+
+// isDefaultSynonym 判断别名是否为默认同义词（不区分大小写）
+func isDefaultSynonym(alias string) bool {
+    _, ok := DefaultSynonyms[strings.ToLower(alias)]
+    return ok
+}
+
+// NormalizeSynonyms 返回实际生效的同义词表：默认同义词加上配置的同义词，
+// 配置中的同名别名（不区分大小写）优先，标准词为空的别名被取消
+func (c *Config) NormalizeSynonyms() map[string]string {
+    synonyms := make(map[string]string, len(DefaultSynonyms)+len(c.Normalize.Synonyms))
+    for alias, canonical := range DefaultSynonyms {
+        synonyms[alias] = canonical
+    }
+    for alias, canonical := range c.Normalize.Synonyms {
+        delete(synonyms, strings.ToLower(alias))
+        if canonical != "" {
+            synonyms[alias] = canonical
+        }
+    }
+    return synonyms
+}
+
+// 消息格式名称
+const (
+    ParseModeMarkdownV2 = "markdownv2"
+    ParseModeHTML       = "html"
+    ParseModePlain      = "plain"
+)
+
+// cleanParseMode 统一消息格式名称为小写，空值表示使用默认格式
+func cleanParseMode(mode string) (string, error) {
+    mode = strings.ToLower(strings.TrimSpace(mode))
+    switch mode {
+    case "", ParseModeMarkdownV2, ParseModeHTML, ParseModePlain:
+        return mode, nil
+    }
+    return "", fmt.Errorf("消息格式 %q 无效，可选值: markdownv2、html、plain", mode)
+}
+
+// ParseModeFor 返回推送目标使用的消息格式：依次查找目标本身、话题所在的群组和全局设置，
+// 都未设置时使用 markdownv2
+func (c *Config) ParseModeFor(target string) string {
+    if mode := c.Telegram.ParseModes[target]; mode != "" {
+        return mode
+    }
+    if chat, threadID, err := ParseChannelTarget(target); err == nil && threadID != 0 {
+        if mode := c.Telegram.ParseModes[chat]; mode != "" {
+            return mode
+        }
+    }
+    if c.Telegram.ParseMode != "" {
+        return c.Telegram.ParseMode
+    }
+    return ParseModeMarkdownV2
+}
+
+// ParseChannelTarget 解析频道推送目标，"@channel" 或 "-100123" 推送到频道或群组，
+// "-100123:45" 推送到群组中ID为45的话题
+func ParseChannelTarget(target string) (chat string, threadID int, err error) {
+    chat = strings.TrimSpace(target)
+    i := strings.LastIndex(chat, ":")
+    if i < 0 {
+        return chat, 0, nil
+    }
+    threadID, err = strconv.Atoi(chat[i+1:])
+    if err != nil || threadID <= 0 || i == 0 {
+        return "", 0, fmt.Errorf("频道 %q 无效，话题应写为 群组ID:话题ID，如 -1001234567890:45", target)
+    }
+    return chat[:i], threadID, nil
+}
+
+// StripsPunctuation 返回匹配前是否去除标点，未设置时默认去除
+func (r *RSSEntry) StripsPunctuation() bool {
+    return r.StripPunctuation == nil || *r.StripPunctuation
+}
+
+// cleanKeywords 去除空白和空项，并检查表达式语法
+func cleanKeywords(keywords []string) ([]string, error) {
+    clean := make([]string, 0, len(keywords))
+    for _, keyword := range keywords {
+        keyword = strings.TrimSpace(keyword)
+        if keyword == "" {
+            continue
+        }
+        if err := filter.Validate(keyword); err != nil {
+            return nil, err
+        }
+        clean = append(clean, keyword)
+    }
+    return clean, nil
+}
+
+// EnsureSubscriptionIDs 为缺少ID或ID重复的订阅生成ID，返回是否有修改
+func (c *Config) EnsureSubscriptionIDs() bool {
+    changed := false
+    used := make(map[string]bool, len(c.RSS))
+    for i := range c.RSS {
+        id := strings.TrimSpace(c.RSS[i].ID)
+        if id == "" || used[id] {
+            id = c.newSubscriptionID(c.RSS[i].URLs, used)
+        }
+        if id != c.RSS[i].ID {
+            c.RSS[i].ID = id
+            changed = true
+        }
+        used[id] = true
+    }
+    return changed
+}
+
+// NewSubscriptionID 为即将添加的订阅生成一个未被使用的ID
+func (c *Config) NewSubscriptionID(urls []string) string {
+    used := make(map[string]bool, len(c.RSS))
+    for _, rss := range c.RSS {
+        used[rss.ID] = true
+    }
+    return c.newSubscriptionID(urls, used)
+}
+
+// newSubscriptionID 根据URL列表生成6位短ID，首字符为字母以免与序号混淆，
+// 与已有ID冲突时加入计数重新生成
+func (c *Config) newSubscriptionID(urls []string, used map[string]bool) string {
+    const letters = "abcdefghijkmnpqrstuvwxyz"
+    const alphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+    seed := strings.Join(urls, ",")
+    for attempt := 0; ; attempt++ {
+        sum := sha1.Sum([]byte(fmt.Sprintf("%s#%d", seed, attempt)))
+        id := []byte{letters[int(sum[0])%len(letters)]}
+        for _, b := range sum[1:6] {
+            id = append(id, alphabet[int(b)%len(alphabet)])
+        }
+        if !used[string(id)] {
+            return string(id)
+        }
+    }
+}
+
+// FindRSS 按订阅ID查找订阅，返回其在 RSS 列表中的下标，找不到时返回 -1
+func (c *Config) FindRSS(id string) int {
+    for i, rss := range c.RSS {
+        if rss.ID == id {
+            return i
+        }
+    }
+    return -1
+}
+
 func LoadFromEnv() *Config {
     config := &Config{}
     
@@ -678,6 +1396,9 @@ func LoadFromEnv() *Config {
         }
     }
 
+    // 环境变量中的订阅没有ID，按URL生成，保证重启后保持不变
+    config.EnsureSubscriptionIDs()
+
     return config
 }
 
@@ -2,24 +2,24 @@ package enhancer
 
 import (
 	"log"
-	"time"
 
+	"rss2tg/internal/model"
 	"rss2tg/internal/webhook"
 )
 
 // MessageHandler 原始消息处理器类型
-type MessageHandler func(title, url, group string, pubDate time.Time, matchedKeywords []string) error
+type MessageHandler func(item *model.Item) error
 
 // EnhancedMessageHandler 增强的消息处理器
 type EnhancedMessageHandler struct {
-	originalHandler func(title, url, group string, pubDate time.Time, matchedKeywords []string) error
+	originalHandler func(item *model.Item) error
 	webhookClient   *webhook.Client
 	multiWebhookClient *webhook.MultiClient
 	formatter       *webhook.Formatter
 }
 
 // NewEnhancedMessageHandler 创建增强的消息处理器（单个 webhook）
-func NewEnhancedMessageHandler(originalHandler func(title, url, group string, pubDate time.Time, matchedKeywords []string) error, webhookClient *webhook.Client) *EnhancedMessageHandler {
+func NewEnhancedMessageHandler(originalHandler func(item *model.Item) error, webhookClient *webhook.Client) *EnhancedMessageHandler {
 	return &EnhancedMessageHandler{
 		originalHandler: originalHandler,
 		webhookClient:   webhookClient,
@@ -28,7 +28,7 @@ func NewEnhancedMessageHandler(originalHandler func(title, url, group string, pu
 }
 
 // NewEnhancedMultiMessageHandler 创建增强的消息处理器（多个 webhook）
-func NewEnhancedMultiMessageHandler(originalHandler func(title, url, group string, pubDate time.Time, matchedKeywords []string) error, multiWebhookClient *webhook.MultiClient) *EnhancedMessageHandler {
+func NewEnhancedMultiMessageHandler(originalHandler func(item *model.Item) error, multiWebhookClient *webhook.MultiClient) *EnhancedMessageHandler {
 	return &EnhancedMessageHandler{
 		originalHandler: originalHandler,
 		multiWebhookClient: multiWebhookClient,
@@ -37,9 +37,9 @@ func NewEnhancedMultiMessageHandler(originalHandler func(title, url, group strin
 }
 
 // HandleMessage 处理消息，同时发送到 Telegram 和 webhook
-func (h *EnhancedMessageHandler) HandleMessage(title, url, group string, pubDate time.Time, matchedKeywords []string) error {
+func (h *EnhancedMessageHandler) HandleMessage(item *model.Item) error {
 	// 首先发送到原有的 Telegram 推送
-	err := h.originalHandler(title, url, group, pubDate, matchedKeywords)
+	err := h.originalHandler(item)
 	if err != nil {
 		log.Printf("Telegram 推送失败: %v", err)
 		// 注意：即使 Telegram 推送失败，我们仍然继续 webhook 推送
@@ -47,11 +47,15 @@ func (h *EnhancedMessageHandler) HandleMessage(title, url, group string, pubDate
 
 	// 异步发送到 webhook（不影响 Telegram 推送）
 	go func() {
-		msg := h.formatter.FormatMessage(title, url, group, pubDate, matchedKeywords)
+		msg := h.formatter.FormatMessage(item)
 		
 		if h.multiWebhookClient != nil {
-			// 使用多 webhook 客户端
-			results := h.multiWebhookClient.Send(msg)
+			// 使用多 webhook 客户端，订阅指定了 webhook 时只发送到这些 webhook
+			client := h.multiWebhookClient
+			if len(item.Route.Webhooks) > 0 {
+				client = client.Select(item.Route.Webhooks)
+			}
+			results := client.Send(msg)
 			for _, result := range results {
 				if result.Success {
 					log.Printf("Webhook [%s] 推送成功", result.Name)
@@ -60,7 +64,10 @@ func (h *EnhancedMessageHandler) HandleMessage(title, url, group string, pubDate
 				}
 			}
 		} else if h.webhookClient != nil {
-			// 使用单个 webhook 客户端（向后兼容）
+			// 使用单个 webhook 客户端（向后兼容），订阅指定了 webhook 但未引用它时不发送
+			if !item.Route.UsesWebhook(model.LegacyWebhook) {
+				return
+			}
 			if err := h.webhookClient.Send(msg); err != nil {
 				log.Printf("Webhook 推送失败: %v", err)
 			}
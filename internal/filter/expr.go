@@ -0,0 +1,424 @@
+// Package filter 实现订阅关键词的布尔表达式，例如：
+//
+//	vps AND (优惠 OR 促销) AND NOT 测评
+//
+// 运算符 AND、OR、NOT 必须大写，优先级从高到低为 NOT、AND、OR，
+// 相邻的两个关键词之间省略运算符时按 AND 处理，
+// 含空格的短语用英文或中文双引号括起来，如 "black friday"。
+//
+// 关键词可以带字段前缀，只在指定字段中匹配，如 title:vps、category:Deals、
+// title:"black friday"，可用字段见 field.go。
+//
+// 以 re: 开头的关键词是正则表达式规则（如 re:\$\d+/yr），整条作为一个规则，
+// 不参与布尔表达式解析。
+//
+// 整条规则末尾可以用 ^数字 指定权重（如 "vps^3"、"vps AND 测评^-5"），
+// 见 weight.go；未指定时权重为 1。正则规则中的 ^ 是锚点，不作为权重解析。
+package filter
+
+import (
+    "fmt"
+    "strings"
+)
+
+// MatchFunc 判断单个关键词是否命中文章
+type MatchFunc func(term string) bool
+
+// Expr 解析后的关键词表达式
+type Expr struct {
+    source string
+    root   node
+}
+
+// SyntaxError 表达式语法错误，Pos 为出错位置（从1开始的字符序号）
+type SyntaxError struct {
+    Expr string
+    Pos  int
+    Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+    return fmt.Sprintf("表达式 %q 第 %d 个字符附近: %s", e.Expr, e.Pos, e.Msg)
+}
+
+// Parse 解析关键词表达式
+func Parse(source string) (*Expr, error) {
+    tokens, err := tokenize(source)
+    if err != nil {
+        return nil, err
+    }
+    p := &parser{source: source, tokens: tokens}
+    if len(tokens) == 0 {
+        return nil, p.errorAt(0, "表达式为空")
+    }
+
+    root, err := p.parseOr()
+    if err != nil {
+        return nil, err
+    }
+    if p.pos < len(p.tokens) {
+        tok := p.tokens[p.pos]
+        if tok.kind == tokenRParen {
+            return nil, p.errorAt(tok.pos, "多余的右括号")
+        }
+        return nil, p.errorAt(tok.pos, fmt.Sprintf("无法识别的 %q", tok.text))
+    }
+    return &Expr{source: source, root: root}, nil
+}
+
+// IsExpression 判断关键词是否使用了表达式语法（运算符、括号或引号），
+// 普通关键词和正则规则按原样进行匹配
+func IsExpression(keyword string) bool {
+    keyword, _, _ = SplitWeight(keyword)
+    if IsRegex(keyword) {
+        return false
+    }
+    tokens, err := tokenize(keyword)
+    if err != nil {
+        // 语法有误的表达式也视为表达式，以便给出错误提示
+        return true
+    }
+    for _, tok := range tokens {
+        if tok.kind != tokenTerm || tok.quoted {
+            return true
+        }
+    }
+    return false
+}
+
+// Validate 检查关键词，普通关键词总是有效，表达式需能正确解析，正则规则需能正确编译；
+// 末尾的权重会先被去掉
+func Validate(keyword string) error {
+    keyword, _, _ = SplitWeight(keyword)
+    if IsRegex(keyword) {
+        _, err := CompileRegex(keyword)
+        return err
+    }
+    terms := []string{keyword}
+    if IsExpression(keyword) {
+        expr, err := Parse(keyword)
+        if err != nil {
+            return err
+        }
+        terms = expr.Terms()
+    }
+    for _, term := range terms {
+        if field, text := SplitField(term); field != "" && text == "" {
+            return fmt.Errorf("关键词 %q 的字段前缀后缺少内容", term)
+        }
+    }
+    return nil
+}
+
+// String 返回表达式原文
+func (e *Expr) String() string {
+    return e.source
+}
+
+// Match 计算表达式，返回是否命中以及命中的关键词（不含 NOT 中的关键词）
+func (e *Expr) Match(match MatchFunc) (bool, []string) {
+    return e.root.eval(match)
+}
+
+// Terms 返回表达式中出现的所有关键词
+func (e *Expr) Terms() []string {
+    var terms []string
+    e.root.terms(&terms)
+    return terms
+}
+
+// node 表达式语法树节点
+type node interface {
+    eval(match MatchFunc) (bool, []string)
+    terms(terms *[]string)
+}
+
+type termNode struct {
+    text string
+}
+
+func (n *termNode) eval(match MatchFunc) (bool, []string) {
+    if match(n.text) {
+        return true, []string{n.text}
+    }
+    return false, nil
+}
+
+func (n *termNode) terms(terms *[]string) {
+    *terms = append(*terms, n.text)
+}
+
+type notNode struct {
+    operand node
+}
+
+func (n *notNode) eval(match MatchFunc) (bool, []string) {
+    ok, _ := n.operand.eval(match)
+    return !ok, nil
+}
+
+func (n *notNode) terms(terms *[]string) {
+    n.operand.terms(terms)
+}
+
+type andNode struct {
+    operands []node
+}
+
+func (n *andNode) eval(match MatchFunc) (bool, []string) {
+    var matched []string
+    for _, operand := range n.operands {
+        ok, terms := operand.eval(match)
+        if !ok {
+            return false, nil
+        }
+        matched = append(matched, terms...)
+    }
+    return true, matched
+}
+
+func (n *andNode) terms(terms *[]string) {
+    for _, operand := range n.operands {
+        operand.terms(terms)
+    }
+}
+
+type orNode struct {
+    operands []node
+}
+
+// eval 计算所有分支，以便报告每个命中分支中的关键词
+func (n *orNode) eval(match MatchFunc) (bool, []string) {
+    result := false
+    var matched []string
+    for _, operand := range n.operands {
+        if ok, terms := operand.eval(match); ok {
+            result = true
+            matched = append(matched, terms...)
+        }
+    }
+    return result, matched
+}
+
+func (n *orNode) terms(terms *[]string) {
+    for _, operand := range n.operands {
+        operand.terms(terms)
+    }
+}
+
+// parser 递归下降解析器
+type parser struct {
+    source string
+    tokens []token
+    pos    int
+}
+
+func (p *parser) errorAt(pos int, msg string) error {
+    return &SyntaxError{Expr: p.source, Pos: pos + 1, Msg: msg}
+}
+
+func (p *parser) peek() *token {
+    if p.pos < len(p.tokens) {
+        return &p.tokens[p.pos]
+    }
+    return nil
+}
+
+// parseOr 解析 and ( OR and )*
+func (p *parser) parseOr() (node, error) {
+    first, err := p.parseAnd()
+    if err != nil {
+        return nil, err
+    }
+    operands := []node{first}
+    for {
+        tok := p.peek()
+        if tok == nil || tok.kind != tokenOr {
+            break
+        }
+        p.pos++
+        next, err := p.parseAnd()
+        if err != nil {
+            return nil, err
+        }
+        operands = append(operands, next)
+    }
+    if len(operands) == 1 {
+        return first, nil
+    }
+    return &orNode{operands: operands}, nil
+}
+
+// parseAnd 解析 unary ( [AND] unary )*，相邻的关键词按 AND 处理
+func (p *parser) parseAnd() (node, error) {
+    first, err := p.parseUnary()
+    if err != nil {
+        return nil, err
+    }
+    operands := []node{first}
+    for {
+        tok := p.peek()
+        if tok == nil || tok.kind == tokenOr || tok.kind == tokenRParen {
+            break
+        }
+        if tok.kind == tokenAnd {
+            p.pos++
+        }
+        next, err := p.parseUnary()
+        if err != nil {
+            return nil, err
+        }
+        operands = append(operands, next)
+    }
+    if len(operands) == 1 {
+        return first, nil
+    }
+    return &andNode{operands: operands}, nil
+}
+
+// parseUnary 解析 NOT unary | primary
+func (p *parser) parseUnary() (node, error) {
+    tok := p.peek()
+    if tok != nil && tok.kind == tokenNot {
+        p.pos++
+        operand, err := p.parseUnary()
+        if err != nil {
+            return nil, err
+        }
+        return &notNode{operand: operand}, nil
+    }
+    return p.parsePrimary()
+}
+
+// parsePrimary 解析 ( or ) | 关键词
+func (p *parser) parsePrimary() (node, error) {
+    tok := p.peek()
+    if tok == nil {
+        last := p.tokens[len(p.tokens)-1]
+        return nil, p.errorAt(last.pos, fmt.Sprintf("%q 后缺少关键词", last.text))
+    }
+
+    switch tok.kind {
+    case tokenTerm:
+        p.pos++
+        return &termNode{text: tok.text}, nil
+    case tokenLParen:
+        p.pos++
+        inner, err := p.parseOr()
+        if err != nil {
+            return nil, err
+        }
+        closing := p.peek()
+        if closing == nil || closing.kind != tokenRParen {
+            return nil, p.errorAt(tok.pos, "缺少对应的右括号")
+        }
+        p.pos++
+        return inner, nil
+    case tokenRParen:
+        if p.pos == 0 || p.tokens[p.pos-1].kind == tokenLParen {
+            return nil, p.errorAt(tok.pos, "括号内缺少关键词")
+        }
+        return nil, p.errorAt(tok.pos, fmt.Sprintf("%q 后缺少关键词", p.tokens[p.pos-1].text))
+    default:
+        if p.pos == 0 {
+            return nil, p.errorAt(tok.pos, fmt.Sprintf("%s 前缺少关键词", tok.text))
+        }
+        return nil, p.errorAt(tok.pos, fmt.Sprintf("%q 后缺少关键词", p.tokens[p.pos-1].text))
+    }
+}
+
+type tokenKind int
+
+const (
+    tokenTerm tokenKind = iota
+    tokenAnd
+    tokenOr
+    tokenNot
+    tokenLParen
+    tokenRParen
+)
+
+type token struct {
+    kind   tokenKind
+    text   string
+    pos    int  // 在原文中的字符序号（从0开始）
+    quoted bool // 是否为引号括起的短语
+}
+
+// closingQuotes 支持的引号及其对应的结束引号
+var closingQuotes = map[rune]rune{
+    '"': '"',
+    '“': '”',
+}
+
+// tokenize 将表达式拆分为运算符、括号和关键词，位置按字符计算
+func tokenize(source string) ([]token, error) {
+    runes := []rune(source)
+    var tokens []token
+
+    for i := 0; i < len(runes); {
+        ch := runes[i]
+        switch {
+        case isSpace(ch):
+            i++
+        case ch == '(' || ch == '（':
+            tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+            i++
+        case ch == ')' || ch == '）':
+            tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+            i++
+        case closingQuotes[ch] != 0:
+            end := i + 1
+            for end < len(runes) && runes[end] != closingQuotes[ch] {
+                end++
+            }
+            if end >= len(runes) {
+                return nil, &SyntaxError{Expr: source, Pos: i + 1, Msg: "引号没有结束"}
+            }
+            text := strings.TrimSpace(string(runes[i+1 : end]))
+            if text == "" {
+                return nil, &SyntaxError{Expr: source, Pos: i + 1, Msg: "引号内缺少关键词"}
+            }
+            tokens = append(tokens, token{kind: tokenTerm, text: text, pos: i, quoted: true})
+            i = end + 1
+        default:
+            start := i
+            for i < len(runes) && !isSpace(runes[i]) && !isDelimiter(runes[i]) {
+                i++
+            }
+            text := string(runes[start:i])
+            // 字段前缀后紧跟引号短语，如 title:"black friday"
+            if strings.HasSuffix(text, ":") && i < len(runes) && closingQuotes[runes[i]] != 0 {
+                end := i + 1
+                for end < len(runes) && runes[end] != closingQuotes[runes[i]] {
+                    end++
+                }
+                if end >= len(runes) {
+                    return nil, &SyntaxError{Expr: source, Pos: i + 1, Msg: "引号没有结束"}
+                }
+                tokens = append(tokens, token{kind: tokenTerm, text: text + strings.TrimSpace(string(runes[i+1:end])), pos: start, quoted: true})
+                i = end + 1
+                continue
+            }
+            kind := tokenTerm
+            switch text {
+            case "AND":
+                kind = tokenAnd
+            case "OR":
+                kind = tokenOr
+            case "NOT":
+                kind = tokenNot
+            }
+            tokens = append(tokens, token{kind: kind, text: text, pos: start})
+        }
+    }
+    return tokens, nil
+}
+
+func isSpace(ch rune) bool {
+    return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '　'
+}
+
+func isDelimiter(ch rune) bool {
+    return ch == '(' || ch == ')' || ch == '（' || ch == '）' || closingQuotes[ch] != 0
+}
@@ -0,0 +1,198 @@
+package filter
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// matchSet 返回只命中给定关键词的 MatchFunc
+func matchSet(terms ...string) MatchFunc {
+    set := make(map[string]bool, len(terms))
+    for _, term := range terms {
+        set[term] = true
+    }
+    return func(term string) bool { return set[term] }
+}
+
+func TestExprMatch(t *testing.T) {
+    tests := []struct {
+        name    string
+        expr    string
+        present []string
+        matched bool
+        terms   []string
+    }{
+        {"AND 全部命中", "vps AND 优惠", []string{"vps", "优惠"}, true, []string{"vps", "优惠"}},
+        {"AND 部分命中", "vps AND 优惠", []string{"vps"}, false, nil},
+        {"OR 报告所有命中分支", "vps OR 独服", []string{"vps", "独服"}, true, []string{"vps", "独服"}},
+        {"OR 一个分支命中", "vps OR 独服", []string{"独服"}, true, []string{"独服"}},
+        {"AND 优先于 OR", "a OR b AND c", []string{"a"}, true, []string{"a"}},
+        {"AND 优先于 OR 右侧不完整", "a OR b AND c", []string{"b"}, false, nil},
+        {"AND 优先于 OR 右侧完整", "a OR b AND c", []string{"b", "c"}, true, []string{"b", "c"}},
+        {"NOT 优先于 AND", "NOT a AND b", []string{"b"}, true, []string{"b"}},
+        {"NOT 优先于 AND 被排除", "NOT a AND b", []string{"a", "b"}, false, nil},
+        {"NOT 中的关键词不报告", "vps AND NOT 测评", []string{"vps"}, true, []string{"vps"}},
+        {"双重 NOT", "NOT NOT a", []string{"a"}, true, nil},
+        {"省略运算符按 AND", "vps 优惠", []string{"vps"}, false, nil},
+        {"省略运算符全部命中", "vps 优惠", []string{"vps", "优惠"}, true, []string{"vps", "优惠"}},
+        {"括号改变优先级", "(a OR b) c", []string{"b", "c"}, true, []string{"b", "c"}},
+        {"括号改变优先级未命中", "(a OR b) AND c", []string{"a"}, false, nil},
+        {"嵌套括号", "vps AND (优惠 OR (促销 AND NOT 测评))", []string{"vps", "促销"}, true, []string{"vps", "促销"}},
+        {"全角括号", "（a OR b）AND c", []string{"a", "c"}, true, []string{"a", "c"}},
+        {"英文引号短语", `"black friday" OR 黑五`, []string{"black friday"}, true, []string{"black friday"}},
+        {"中文引号短语", "“黑色 星期五” AND vps", []string{"黑色 星期五", "vps"}, true, []string{"黑色 星期五", "vps"}},
+        {"引号内的运算符作为普通文字", `"AND OR"`, []string{"AND OR"}, true, []string{"AND OR"}},
+        {"字段前缀", "title:vps AND category:Deals", []string{"title:vps", "category:Deals"}, true, []string{"title:vps", "category:Deals"}},
+        {"字段前缀加引号短语", `title:"black friday"`, []string{"title:black friday"}, true, []string{"title:black friday"}},
+        {"小写运算符是关键词", "vps and 优惠", []string{"vps", "and", "优惠"}, true, []string{"vps", "and", "优惠"}},
+        {"全角空格分隔", "vps　优惠", []string{"vps", "优惠"}, true, []string{"vps", "优惠"}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            expr, err := Parse(tt.expr)
+            if err != nil {
+                t.Fatalf("Parse(%q) 返回错误: %v", tt.expr, err)
+            }
+            matched, terms := expr.Match(matchSet(tt.present...))
+            if matched != tt.matched {
+                t.Errorf("Parse(%q).Match() = %v，期望 %v", tt.expr, matched, tt.matched)
+            }
+            if !reflect.DeepEqual(terms, tt.terms) {
+                t.Errorf("Parse(%q).Match() 命中关键词 = %q，期望 %q", tt.expr, terms, tt.terms)
+            }
+        })
+    }
+}
+
+func TestExprTerms(t *testing.T) {
+    tests := []struct {
+        expr  string
+        terms []string
+    }{
+        {"vps AND (优惠 OR 促销) AND NOT 测评", []string{"vps", "优惠", "促销", "测评"}},
+        {`"black friday" 黑五`, []string{"black friday", "黑五"}},
+        {"“  黑色 星期五 ”", []string{"黑色 星期五"}},
+        {`title:"  black friday  " OR desc:vps`, []string{"title:black friday", "desc:vps"}},
+    }
+
+    for _, tt := range tests {
+        expr, err := Parse(tt.expr)
+        if err != nil {
+            t.Fatalf("Parse(%q) 返回错误: %v", tt.expr, err)
+        }
+        if terms := expr.Terms(); !reflect.DeepEqual(terms, tt.terms) {
+            t.Errorf("Parse(%q).Terms() = %q，期望 %q", tt.expr, terms, tt.terms)
+        }
+    }
+}
+
+func TestParseErrors(t *testing.T) {
+    tests := []struct {
+        expr string
+        pos  int
+        msg  string
+    }{
+        {"", 1, "表达式为空"},
+        {"   ", 1, "表达式为空"},
+        {"vps AND", 5, `"AND" 后缺少关键词`},
+        {"vps OR", 5, `"OR" 后缺少关键词`},
+        {"vps AND NOT", 9, `"NOT" 后缺少关键词`},
+        {"AND vps", 1, "AND 前缺少关键词"},
+        {"OR vps", 1, "OR 前缺少关键词"},
+        {"vps OR OR 优惠", 8, `"OR" 后缺少关键词`},
+        {"vps AND OR 优惠", 9, `"AND" 后缺少关键词`},
+        {"(vps OR 优惠", 1, "缺少对应的右括号"},
+        {"vps OR 优惠)", 10, "多余的右括号"},
+        {"()", 2, "括号内缺少关键词"},
+        {"vps AND ()", 10, "括号内缺少关键词"},
+        {"(vps AND)", 9, `"AND" 后缺少关键词`},
+        {`"black friday`, 1, "引号没有结束"},
+        {"“黑色星期五", 1, "引号没有结束"},
+        {`vps AND "  "`, 9, "引号内缺少关键词"},
+        {`title:"black friday`, 7, "引号没有结束"},
+    }
+
+    for _, tt := range tests {
+        _, err := Parse(tt.expr)
+        if err == nil {
+            t.Errorf("Parse(%q) 应返回错误", tt.expr)
+            continue
+        }
+        syntaxErr, ok := err.(*SyntaxError)
+        if !ok {
+            t.Errorf("Parse(%q) 返回的错误类型为 %T，期望 *SyntaxError", tt.expr, err)
+            continue
+        }
+        if syntaxErr.Pos != tt.pos || syntaxErr.Msg != tt.msg {
+            t.Errorf("Parse(%q) 错误 = 第 %d 个字符 %q，期望第 %d 个字符 %q", tt.expr, syntaxErr.Pos, syntaxErr.Msg, tt.pos, tt.msg)
+        }
+    }
+}
+
+func TestIsExpression(t *testing.T) {
+    tests := []struct {
+        keyword string
+        want    bool
+    }{
+        {"vps", false},
+        {"title:vps", false},
+        {"vps^3", false},
+        {"vps 优惠", false},
+        {"vps AND 优惠", true},
+        {"vps OR 优惠^2", true},
+        {"NOT 测评", true},
+        {"(vps)", true},
+        {`"black friday"`, true},
+        {"“黑五”", true},
+        {`title:"black friday"`, true},
+        {`"未结束的引号`, true},
+        {`re:\$\d+/yr`, false},
+        {"re:(AND|OR)", false},
+    }
+
+    for _, tt := range tests {
+        if got := IsExpression(tt.keyword); got != tt.want {
+            t.Errorf("IsExpression(%q) = %v，期望 %v", tt.keyword, got, tt.want)
+        }
+    }
+}
+
+func TestValidate(t *testing.T) {
+    tests := []struct {
+        keyword string
+        errText string // 为空表示应该有效
+    }{
+        {"vps", ""},
+        {"vps^3", ""},
+        {"测评^-5", ""},
+        {"vps AND (优惠 OR 促销)^2", ""},
+        {"title:vps OR desc:优惠", ""},
+        {`title:"black friday"`, ""},
+        {`re:\$\d+/yr`, ""},
+        {"re:^2024", ""},
+        {"re:foo|^10", ""},
+        {"re:(?m)^1", ""},
+        {"vps AND", "缺少关键词"},
+        {"vps AND^2", "缺少关键词"},
+        {"(vps OR 优惠", "缺少对应的右括号"},
+        {"title:", "字段前缀后缺少内容"},
+        {"vps OR title:", "字段前缀后缺少内容"},
+        {"re:(", "正则表达式"},
+        {"re:", "正则表达式为空"},
+    }
+
+    for _, tt := range tests {
+        err := Validate(tt.keyword)
+        if tt.errText == "" {
+            if err != nil {
+                t.Errorf("Validate(%q) 返回错误: %v", tt.keyword, err)
+            }
+            continue
+        }
+        if err == nil || !strings.Contains(err.Error(), tt.errText) {
+            t.Errorf("Validate(%q) = %v，期望包含 %q 的错误", tt.keyword, err, tt.errText)
+        }
+    }
+}
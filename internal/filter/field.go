@@ -0,0 +1,46 @@
+package filter
+
+import "strings"
+
+// 可用于匹配的文章字段
+const (
+    FieldTitle       = "title"
+    FieldDescription = "description"
+    FieldContent     = "content"
+    FieldAuthor      = "author"
+    FieldCategory    = "category" // 分类按整体相等匹配，不区分大小写
+)
+
+// DefaultFields 未配置 match_fields 时匹配的字段
+var DefaultFields = []string{FieldTitle, FieldDescription}
+
+// fieldAliases 字段名及其简写
+var fieldAliases = map[string]string{
+    "title":       FieldTitle,
+    "desc":        FieldDescription,
+    "description": FieldDescription,
+    "content":     FieldContent,
+    "author":      FieldAuthor,
+    "category":    FieldCategory,
+    "cat":         FieldCategory,
+}
+
+// NormalizeField 将字段名或简写转换为标准字段名，不支持的字段返回 false
+func NormalizeField(name string) (string, bool) {
+    field, ok := fieldAliases[strings.ToLower(strings.TrimSpace(name))]
+    return field, ok
+}
+
+// SplitField 拆分带字段前缀的关键词，如 "title:vps" 返回 ("title", "vps")；
+// 没有前缀或前缀不是支持的字段时返回空字段名和原关键词
+func SplitField(term string) (field, text string) {
+    i := strings.Index(term, ":")
+    if i <= 0 {
+        return "", term
+    }
+    field, ok := NormalizeField(term[:i])
+    if !ok {
+        return "", term
+    }
+    return field, strings.TrimSpace(term[i+1:])
+}
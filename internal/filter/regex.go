@@ -0,0 +1,32 @@
+package filter
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+)
+
+// RegexPrefix 正则规则关键词的前缀
+const RegexPrefix = "re:"
+
+// IsRegex 判断关键词是否为 re: 开头的正则规则
+func IsRegex(keyword string) bool {
+    return strings.HasPrefix(keyword, RegexPrefix)
+}
+
+// CompileRegex 编译 re: 开头的正则规则关键词
+func CompileRegex(keyword string) (*regexp.Regexp, error) {
+    return CompilePattern(strings.TrimPrefix(keyword, RegexPrefix))
+}
+
+// CompilePattern 编译正则表达式，默认不区分大小写
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+    if strings.TrimSpace(pattern) == "" {
+        return nil, fmt.Errorf("正则表达式为空")
+    }
+    re, err := regexp.Compile("(?i)" + pattern)
+    if err != nil {
+        return nil, fmt.Errorf("正则表达式 %q 无效: %v", pattern, err)
+    }
+    return re, nil
+}
@@ -0,0 +1,40 @@
+package filter
+
+import (
+    "math"
+    "strconv"
+    "strings"
+)
+
+// WeightSeparator 关键词与权重之间的分隔符，如 "vps^3"、"测评^-5"
+const WeightSeparator = "^"
+
+// DefaultWeight 未指定权重时关键词规则的权重
+const DefaultWeight = 1.0
+
+// SplitWeight 拆分关键词末尾的权重，如 "vps AND 优惠^2" 返回 ("vps AND 优惠", 2, true)；
+// 末尾不是 ^数字 时返回原关键词和默认权重。
+// re: 正则规则中的 ^ 是锚点，不解析权重，需要权重时请使用 regex 列表的 weight
+func SplitWeight(keyword string) (text string, weight float64, ok bool) {
+    if IsRegex(keyword) {
+        return keyword, DefaultWeight, false
+    }
+    i := strings.LastIndex(keyword, WeightSeparator)
+    if i <= 0 {
+        return keyword, DefaultWeight, false
+    }
+    weight, err := strconv.ParseFloat(strings.TrimSpace(keyword[i+len(WeightSeparator):]), 64)
+    if err != nil || math.IsNaN(weight) || math.IsInf(weight, 0) {
+        return keyword, DefaultWeight, false
+    }
+    text = strings.TrimSpace(keyword[:i])
+    if text == "" {
+        return keyword, DefaultWeight, false
+    }
+    return text, weight, true
+}
+
+// FormatWeight 格式化权重或分数，整数不带小数位
+func FormatWeight(weight float64) string {
+    return strconv.FormatFloat(weight, 'f', -1, 64)
+}
@@ -0,0 +1,64 @@
+package filter
+
+import "testing"
+
+func TestSplitWeight(t *testing.T) {
+    tests := []struct {
+        keyword string
+        text    string
+        weight  float64
+        ok      bool
+    }{
+        {"vps", "vps", 1, false},
+        {"vps^3", "vps", 3, true},
+        {"vps^1.5", "vps", 1.5, true},
+        {"vps^0", "vps", 0, true},
+        {"测评^-5", "测评", -5, true},
+        {"vps ^ 2", "vps", 2, true},
+        {"vps AND 优惠^2", "vps AND 优惠", 2, true},
+        {"(vps OR 独服)^-0.5", "(vps OR 独服)", -0.5, true},
+        {"a^b^2", "a^b", 2, true},
+        {`re:\$\d+/yr^2`, `re:\$\d+/yr^2`, 1, false},
+        {"re:^vps", "re:^vps", 1, false},
+        {"re:^vps$", "re:^vps$", 1, false},
+        {"re:^2024", "re:^2024", 1, false},
+        {"re:foo|^10", "re:foo|^10", 1, false},
+        {"re:(?m)^1", "re:(?m)^1", 1, false},
+        {"^3", "^3", 1, false},
+        {"  ^3", "  ^3", 1, false},
+        {"vps^", "vps^", 1, false},
+        {"vps^abc", "vps^abc", 1, false},
+        {"vps^2x", "vps^2x", 1, false},
+        {"vps^NaN", "vps^NaN", 1, false},
+        {"vps^Inf", "vps^Inf", 1, false},
+        {"vps^-Inf", "vps^-Inf", 1, false},
+    }
+
+    for _, tt := range tests {
+        text, weight, ok := SplitWeight(tt.keyword)
+        if text != tt.text || weight != tt.weight || ok != tt.ok {
+            t.Errorf("SplitWeight(%q) = (%q, %v, %v)，期望 (%q, %v, %v)", tt.keyword, text, weight, ok, tt.text, tt.weight, tt.ok)
+        }
+    }
+}
+
+func TestFormatWeight(t *testing.T) {
+    tests := []struct {
+        weight float64
+        want   string
+    }{
+        {0, "0"},
+        {1, "1"},
+        {2, "2"},
+        {-5, "-5"},
+        {1.5, "1.5"},
+        {-0.25, "-0.25"},
+        {3.5 + 2, "5.5"},
+    }
+
+    for _, tt := range tests {
+        if got := FormatWeight(tt.weight); got != tt.want {
+            t.Errorf("FormatWeight(%v) = %q，期望 %q", tt.weight, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,116 @@
+// Package model 定义在 rss、enhancer、bot 与 webhook 之间传递的文章模型
+package model
+
+import (
+    "regexp"
+    "strings"
+    "time"
+    "unicode/utf8"
+)
+
+// Enclosure 文章附件，如图片、音频或视频
+type Enclosure struct {
+    URL    string
+    Type   string // MIME 类型，如 image/jpeg
+    Length string // 附件大小（字节），来自源数据，可能为空
+}
+
+// IsImage 判断附件是否为图片
+func (e Enclosure) IsImage() bool {
+    return strings.HasPrefix(strings.ToLower(e.Type), "image/")
+}
+
+// Item 一篇待推送的文章，包含 Feed 中解析出的全部常用字段
+type Item struct {
+    SubscriptionID  string              // 所属订阅ID
+    FeedTitle       string              // Feed 的标题
+    Group           string              // 订阅分组
+    Title           string
+    Link            string
+    GUID            string
+    Description     string              // 摘要（可能包含 HTML）
+    Content         string              // 全文（可能包含 HTML）
+    Author          string              // 作者，多个作者以逗号分隔
+    Categories      []string
+    Image           string              // 缩略图或封面图地址
+    Enclosures      []Enclosure
+    Published       time.Time           // 文章时间：发布时间、更新时间或首次发现时间
+    Updated         time.Time           // 更新时间，源数据没有时为零值
+    MatchedKeywords []string            // 匹配到的关键词
+    FuzzyKeywords   []string            // 匹配到的关键词中通过模糊匹配命中的部分
+    Score           float64             // 命中关键词的权重之和，未配置关键词时为0
+    Route           Route               // 推送目标，为空时推送给全局的用户、频道和 webhook
+    Extensions      map[string][]string // 扩展字段，键为 "前缀:名称"（如 media:thumbnail），自定义元素直接使用名称
+}
+
+// IsFuzzy 判断命中的关键词是否来自模糊匹配
+func (i *Item) IsFuzzy(keyword string) bool {
+    for _, fuzzy := range i.FuzzyKeywords {
+        if fuzzy == keyword {
+            return true
+        }
+    }
+    return false
+}
+
+// Images 返回文章的所有图片地址，封面图在前，已去重
+func (i *Item) Images() []string {
+    var images []string
+    seen := make(map[string]bool)
+    add := func(url string) {
+        if url == "" || seen[url] {
+            return
+        }
+        seen[url] = true
+        images = append(images, url)
+    }
+
+    add(i.Image)
+    for _, enclosure := range i.Enclosures {
+        if enclosure.IsImage() {
+            add(enclosure.URL)
+        }
+    }
+    return images
+}
+
+// Extension 返回指定扩展字段的第一个值，不存在时返回空字符串
+func (i *Item) Extension(name string) string {
+    if values := i.Extensions[name]; len(values) > 0 {
+        return values[0]
+    }
+    return ""
+}
+
+// Summary 返回去除 HTML 标签后的摘要，优先使用 Description，
+// 没有时使用 Content，超过 maxRunes 个字符时截断，maxRunes <= 0 表示不截断
+func (i *Item) Summary(maxRunes int) string {
+    text := StripHTML(i.Description)
+    if text == "" {
+        text = StripHTML(i.Content)
+    }
+    if maxRunes > 0 && utf8.RuneCountInString(text) > maxRunes {
+        text = string([]rune(text)[:maxRunes]) + "…"
+    }
+    return text
+}
+
+var (
+    htmlTagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+    htmlEntities   = strings.NewReplacer(
+        "&nbsp;", " ",
+        "&amp;", "&",
+        "&lt;", "<",
+        "&gt;", ">",
+        "&quot;", "\"",
+        "&#39;", "'",
+        "&#34;", "\"",
+    )
+)
+
+// StripHTML 去除 HTML 标签和常见实体，并规范化空白字符
+func StripHTML(text string) string {
+    text = htmlTagPattern.ReplaceAllString(text, " ")
+    text = htmlEntities.Replace(text)
+    return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,39 @@
+package model
+
+import "strings"
+
+// LegacyWebhook 推送目标中引用旧版单个 webhook 配置时使用的名称
+const LegacyWebhook = "webhook"
+
+// Route 文章的推送目标，为空的部分使用全局设置
+type Route struct {
+    Users    []string // 接收推送的用户ID
+    Channels []string // 接收推送的频道
+    Webhooks []string // 使用的 webhook 名称
+}
+
+// HasTelegram 判断是否指定了 Telegram 推送目标（用户或频道）
+func (r Route) HasTelegram() bool {
+    return len(r.Users) > 0 || len(r.Channels) > 0
+}
+
+// UsesWebhook 判断是否推送到指定名称的 webhook，未指定 webhook 时推送到所有 webhook
+func (r Route) UsesWebhook(name string) bool {
+    if len(r.Webhooks) == 0 {
+        return true
+    }
+    for _, webhook := range r.Webhooks {
+        if webhook == name {
+            return true
+        }
+    }
+    return false
+}
+
+// Key 返回推送目标的标识，推送目标相同时标识相同，全局设置为空字符串
+func (r Route) Key() string {
+    if len(r.Users) == 0 && len(r.Channels) == 0 && len(r.Webhooks) == 0 {
+        return ""
+    }
+    return strings.Join(r.Users, ",") + "|" + strings.Join(r.Channels, ",") + "|" + strings.Join(r.Webhooks, ",")
+}
@@ -0,0 +1,151 @@
+// Package msgtemplate 使用 text/template 渲染推送消息，模板可以访问文章的全部字段，
+// 如 {{.Title}}、{{.Link}}、{{.MatchedKeywords}}、{{.Summary 200}}、{{.Extension "media:thumbnail"}}
+package msgtemplate
+
+import (
+    "bytes"
+    "fmt"
+    "strings"
+    "text/template"
+    "time"
+    "unicode/utf8"
+
+    "rss2tg/internal/filter"
+    "rss2tg/internal/model"
+)
+
+// Template 解析后的消息模板，可以并发渲染
+type Template struct {
+    tmpl *template.Template
+}
+
+// Markup 消息格式的转义和标记方式，由发送方按消息格式（MarkdownV2、HTML 或纯文本）提供，
+// 模板使用 bold、link 等辅助函数时同一个模板可以渲染为不同的格式
+type Markup interface {
+    Escape(text string) string    // 转义普通文本中的特殊字符
+    Bold(text string) string      // 加粗，参数为未转义的文本
+    Italic(text string) string    // 斜体，参数为未转义的文本
+    Code(text string) string      // 等宽文本，参数为未转义的文本
+    Link(text, url string) string // 链接，参数为未转义的文本和地址
+}
+
+// Plain 纯文本格式，不转义也不添加任何标记
+var Plain Markup = plainMarkup{}
+
+type plainMarkup struct{}
+
+func (plainMarkup) Escape(text string) string { return text }
+func (plainMarkup) Bold(text string) string   { return text }
+func (plainMarkup) Italic(text string) string { return text }
+func (plainMarkup) Code(text string) string   { return text }
+
+func (plainMarkup) Link(text, url string) string {
+    if text == "" || text == url {
+        return url
+    }
+    return text + " (" + url + ")"
+}
+
+// location 消息中的时间统一使用北京时间
+var location = loadLocation()
+
+func loadLocation() *time.Location {
+    loc, err := time.LoadLocation("Asia/Shanghai")
+    if err != nil {
+        return time.FixedZone("CST", 8*3600)
+    }
+    return loc
+}
+
+// funcs 模板中可用的辅助函数，escape、bold 等在渲染时使用消息格式对应的实现
+func funcs(markup Markup) template.FuncMap {
+    return template.FuncMap{
+        "escape":   markup.Escape,
+        "bold":     markup.Bold,
+        "italic":   markup.Italic,
+        "code":     markup.Code,
+        "link":     markup.Link,
+        "truncate": truncate,
+        "date":     formatDate,
+        "join":     join,
+        "strip":    model.StripHTML,
+        "number":   filter.FormatWeight,
+    }
+}
+
+// Parse 解析模板，并使用示例文章试渲染一次，字段名或函数参数错误时返回错误
+func Parse(name, text string) (*Template, error) {
+    tmpl, err := template.New(name).Funcs(funcs(Plain)).Parse(text)
+    if err != nil {
+        return nil, err
+    }
+    t := &Template{tmpl: tmpl}
+    if _, err := t.Execute(SampleItem(), Plain); err != nil {
+        return nil, err
+    }
+    return t, nil
+}
+
+// Execute 使用文章渲染模板，markup 为消息格式对应的转义和标记方式
+func (t *Template) Execute(item *model.Item, markup Markup) (string, error) {
+    tmpl, err := t.tmpl.Clone()
+    if err != nil {
+        return "", err
+    }
+    var buf bytes.Buffer
+    if err := tmpl.Funcs(funcs(markup)).Execute(&buf, item); err != nil {
+        return "", err
+    }
+    text := strings.TrimSpace(buf.String())
+    if text == "" {
+        return "", fmt.Errorf("模板渲染结果为空")
+    }
+    return text, nil
+}
+
+// truncate 截断到 n 个字符，超出时末尾加省略号，用法：{{.Title | truncate 50}}
+func truncate(n int, text string) string {
+    if n <= 0 || utf8.RuneCountInString(text) <= n {
+        return text
+    }
+    return string([]rune(text)[:n]) + "…"
+}
+
+// formatDate 按 Go 时间格式以北京时间格式化，零值返回空字符串，
+// 用法：{{.Published | date "2006-01-02 15:04"}}
+func formatDate(layout string, t time.Time) string {
+    if t.IsZero() {
+        return ""
+    }
+    return t.In(location).Format(layout)
+}
+
+// join 用分隔符连接列表，用法：{{join ", " .Categories}}
+func join(sep string, items []string) string {
+    return strings.Join(items, sep)
+}
+
+// SampleItem 返回各字段都有值的示例文章，用于校验模板和预览
+func SampleItem() *model.Item {
+    published := time.Date(2024, 11, 29, 20, 30, 0, 0, location)
+    return &model.Item{
+        SubscriptionID:  "a1b2c3",
+        FeedTitle:       "示例订阅",
+        Group:           "技术资讯",
+        Title:           "[黑五] 示例 VPS 年付 $10.99 限时优惠",
+        Link:            "https://example.com/posts/1?id=1&ref=rss",
+        GUID:            "https://example.com/posts/1",
+        Description:     "<p>2核 2G 内存，<b>年付 $10.99</b>，限量 100 台。</p>",
+        Content:         "<p>2核 2G 内存，<b>年付 $10.99</b>，限量 100 台，支持按小时计费。</p>",
+        Author:          "示例作者",
+        Categories:      []string{"Deals", "VPS"},
+        Image:           "https://example.com/images/1.jpg",
+        Enclosures:      []model.Enclosure{{URL: "https://example.com/images/1.jpg", Type: "image/jpeg"}},
+        Published:       published,
+        Updated:         published.Add(time.Hour),
+        MatchedKeywords: []string{"vps", "优惠", "hetzner"},
+        FuzzyKeywords:   []string{"hetzner"},
+        Score:           3.5,
+        Extensions:      map[string][]string{"media:thumbnail": {"https://example.com/images/1-thumb.jpg"}},
+    }
+}
@@ -0,0 +1,114 @@
+package rss
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/mmcdole/gofeed"
+)
+
+// DryRunItem 试运行中单篇文章的匹配结果
+type DryRunItem struct {
+    Title      string
+    Link       string
+    Matched    bool     // 按当前规则是否会推送
+    Keywords   []string // 命中的关键词
+    FuzzyHits  []string // 模糊匹配详情，如 "hetzner≈hetzer"
+    Penalties  []string // 命中的负权重关键词
+    Score      float64  // 命中关键词的权重之和
+    Excluded   []string // 命中的排除词或全局屏蔽词
+    ExcludedBy string   // 排除来源：排除词或全局屏蔽词
+    Stale      bool     // 超过订阅的最大时效，实际运行时不会推送
+    Sent       bool     // 已推送过，实际运行时会跳过
+}
+
+// DryRunResult 一个URL的试运行结果
+type DryRunResult struct {
+    SubscriptionID string // 使用其规则的订阅，为空表示只检查全局屏蔽词
+    URL            string
+    FeedTitle      string
+    MinScore       float64 // 订阅的分数阈值，总分超过该值才推送
+    Items          []DryRunItem
+    Err            error // 抓取或解析失败的原因
+}
+
+// DryRun 立即抓取订阅或URL，按当前规则检查所有文章，不推送也不标记任何文章。
+// 只指定 id 时检查订阅的所有URL；指定 url 时只检查该URL，
+// 此时 id 为空则使用包含该URL的订阅，都不包含时只检查全局屏蔽词
+func (m *Manager) DryRun(id, url string) ([]DryRunResult, error) {
+    m.mu.Lock()
+    var feed *Feed
+    for _, f := range m.feeds {
+        if (id != "" && f.ID == id) || (id == "" && url != "" && contains(f.URLs, url)) {
+            feed = f
+            break
+        }
+    }
+    m.mu.Unlock()
+
+    if feed == nil {
+        if id != "" {
+            return nil, fmt.Errorf("订阅 [%s] 不存在", id)
+        }
+        if url == "" {
+            return nil, fmt.Errorf("未指定订阅或URL")
+        }
+        // 不属于任何订阅的URL，没有关键词规则，只检查全局屏蔽词
+        feed = newFeed("", Config{URLs: []string{url}})
+    }
+
+    urls := feed.URLs
+    if url != "" {
+        urls = []string{url}
+    }
+
+    results := make([]DryRunResult, 0, len(urls))
+    for _, u := range urls {
+        results = append(results, m.dryRunURL(feed, u))
+    }
+    return results, nil
+}
+
+// dryRunURL 抓取单个URL并检查其中的文章，不使用条件请求，也不记录健康状态
+func (m *Manager) dryRunURL(feed *Feed, url string) DryRunResult {
+    result := DryRunResult{SubscriptionID: feed.ID, URL: url, MinScore: feed.MinScore}
+
+    fetched, err := m.fetchFeed(url, "")
+    if err != nil {
+        result.Err = err
+        return result
+    }
+    parsedFeed := fetched.feed
+    result.FeedTitle = parsedFeed.Title
+
+    for _, item := range parsedFeed.Items {
+        key := itemKey(item, feed)
+        pubDate := dryRunItemDate(item)
+        entry := newItem(feed, parsedFeed, item, pubDate)
+        match := m.matchKeywords(entry, feed)
+
+        result.Items = append(result.Items, DryRunItem{
+            Title:      item.Title,
+            Link:       entry.Link,
+            Matched:    match.matched,
+            Keywords:   match.keywords,
+            FuzzyHits:  match.fuzzyHits,
+            Penalties:  match.penalties,
+            Score:      match.score,
+            Excluded:   match.excluded,
+            ExcludedBy: match.excludedBy,
+            Stale:      feed.MaxAge > 0 && time.Since(pubDate) > feed.MaxAge,
+            Sent:       m.wasSent(feed, item, key),
+        })
+    }
+    return result
+}
+
+// dryRunItemDate 返回文章时间，没有发布和更新时间时使用当前时间，
+// 避免像实际运行一样记录首次发现时间
+func dryRunItemDate(item *gofeed.Item) time.Time {
+    if t := itemSortTime(item); !t.IsZero() {
+        return t
+    }
+    return time.Now()
+}
@@ -0,0 +1,130 @@
+package rss
+
+import (
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mmcdole/gofeed"
+    "rss2tg/internal/model"
+    "rss2tg/internal/urlutil"
+)
+
+const (
+    defaultDuplicateWindow     = 24 * time.Hour // 默认跨订阅去重窗口
+    defaultDuplicateSimilarity = 0.85           // 默认标题相似度阈值
+    minSimilarityTitleLength   = 6              // 标题短于此长度时只按完全相同判断
+)
+
+// deliveredItem 记录窗口期内已推送文章的特征
+type deliveredItem struct {
+    key       string
+    link      string
+    title     string
+    bigrams   map[string]bool
+    source    string
+    route     string
+    delivered time.Time
+}
+
+// duplicateIndex 保存最近推送的文章，用于检测不同订阅中的同一篇文章
+type duplicateIndex struct {
+    items []*deliveredItem
+    mu    sync.Mutex
+}
+
+// checkDuplicate 检查文章是否与窗口期内推送到相同目标的文章重复，推送目标不同的订阅互不影响；
+// 不重复时立即登记该文章，保证多个订阅并发检查时只有第一篇会被推送
+func (m *Manager) checkDuplicate(item *gofeed.Item, key, source string, route model.Route) *deliveredItem {
+    options := m.getOptions()
+    if !options.CrossFeedDedup {
+        return nil
+    }
+
+    window := options.DuplicateWindow
+    if window <= 0 {
+        window = defaultDuplicateWindow
+    }
+    threshold := options.DuplicateSimilarity
+    if threshold <= 0 || threshold > 1 {
+        threshold = defaultDuplicateSimilarity
+    }
+
+    candidate := &deliveredItem{
+        key:       key,
+        title:     strings.ReplaceAll(normalizeText(item.Title), " ", ""),
+        source:    source,
+        route:     route.Key(),
+        delivered: time.Now(),
+    }
+    if item.Link != "" {
+        candidate.link = urlutil.Normalize(item.Link, nil)
+    }
+    candidate.bigrams = titleBigrams(candidate.title)
+
+    d := &m.duplicates
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    // 清理窗口期之外的记录
+    cutoff := candidate.delivered.Add(-window)
+    kept := d.items[:0]
+    for _, existing := range d.items {
+        if existing.delivered.After(cutoff) {
+            kept = append(kept, existing)
+        }
+    }
+    d.items = kept
+
+    for _, existing := range d.items {
+        // 同一篇文章（例如上次发送失败后重试）不视为重复
+        if existing.key == key {
+            return nil
+        }
+        if existing.route != candidate.route {
+            continue
+        }
+        if candidate.link != "" && candidate.link == existing.link {
+            return existing
+        }
+        if candidate.title == "" || existing.title == "" {
+            continue
+        }
+        if candidate.title == existing.title {
+            return existing
+        }
+        if len([]rune(candidate.title)) < minSimilarityTitleLength {
+            continue
+        }
+        if jaccard(candidate.bigrams, existing.bigrams) >= threshold {
+            return existing
+        }
+    }
+
+    d.items = append(d.items, candidate)
+    return nil
+}
+
+// titleBigrams 将标准化后的标题拆分为字符二元组，中英文标题均适用
+func titleBigrams(title string) map[string]bool {
+    runes := []rune(title)
+    bigrams := make(map[string]bool)
+    for i := 0; i+1 < len(runes); i++ {
+        bigrams[string(runes[i:i+2])] = true
+    }
+    return bigrams
+}
+
+// jaccard 计算两个集合的 Jaccard 相似度
+func jaccard(a, b map[string]bool) float64 {
+    if len(a) == 0 || len(b) == 0 {
+        return 0
+    }
+    intersection := 0
+    for k := range a {
+        if b[k] {
+            intersection++
+        }
+    }
+    return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
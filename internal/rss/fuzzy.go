@@ -0,0 +1,96 @@
+package rss
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// fuzzyThreshold 返回关键词中单个词允许的最大编辑距离：
+// 3个字符及以下不允许误差，4-6个字符允许1个，7个字符及以上允许2个。
+// 含汉字或数字的词（如型号、价格）必须完全一致
+func fuzzyThreshold(word string) int {
+    for _, ch := range word {
+        if unicode.IsNumber(ch) || unicode.Is(unicode.Han, ch) {
+            return 0
+        }
+    }
+    switch n := utf8.RuneCountInString(word); {
+    case n <= 3:
+        return 0
+    case n <= 6:
+        return 1
+    default:
+        return 2
+    }
+}
+
+// fuzzyWordMatch 检查关键词的分词结果是否以相近的形式连续出现在文本的分词结果中，
+// 每个词的编辑距离都不超过其阈值即视为命中，返回文本中相近的词
+func fuzzyWordMatch(words, keywordWords []string) (string, bool) {
+    if len(keywordWords) == 0 {
+        return "", false
+    }
+    for i := 0; i+len(keywordWords) <= len(words); i++ {
+        matched := true
+        for j, keywordWord := range keywordWords {
+            if editDistance(words[i+j], keywordWord, fuzzyThreshold(keywordWord)) < 0 {
+                matched = false
+                break
+            }
+        }
+        if matched {
+            return strings.Join(words[i:i+len(keywordWords)], " "), true
+        }
+    }
+    return "", false
+}
+
+// editDistance 计算两个词的编辑距离（Levenshtein），超过 max 时返回 -1
+func editDistance(a, b string, max int) int {
+    if a == b {
+        return 0
+    }
+    if max == 0 {
+        return -1
+    }
+    ra, rb := []rune(a), []rune(b)
+    if diff := len(ra) - len(rb); diff > max || -diff > max {
+        return -1
+    }
+
+    prev := make([]int, len(rb)+1)
+    curr := make([]int, len(rb)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(ra); i++ {
+        curr[0] = i
+        rowMin := curr[0]
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            curr[j] = prev[j-1] + cost
+            if prev[j]+1 < curr[j] {
+                curr[j] = prev[j] + 1
+            }
+            if curr[j-1]+1 < curr[j] {
+                curr[j] = curr[j-1] + 1
+            }
+            if curr[j] < rowMin {
+                rowMin = curr[j]
+            }
+        }
+        // 整行都已超过阈值，不可能再回到阈值以内
+        if rowMin > max {
+            return -1
+        }
+        prev, curr = curr, prev
+    }
+    if prev[len(rb)] > max {
+        return -1
+    }
+    return prev[len(rb)]
+}
@@ -0,0 +1,95 @@
+package rss
+
+import "testing"
+
+func TestFuzzyThreshold(t *testing.T) {
+    tests := []struct {
+        word string
+        want int
+    }{
+        {"vps", 0},
+        {"aws", 0},
+        {"node", 1},
+        {"hetzner", 2},
+        {"linode", 1},
+        {"cloudflare", 2},
+        {"ryzen9", 0},
+        {"9950x", 0},
+        {"优惠", 0},
+        {"黑色星期五", 0},
+        {"ｖｐｓ", 0},
+        {"straße", 1},
+    }
+
+    for _, tt := range tests {
+        if got := fuzzyThreshold(tt.word); got != tt.want {
+            t.Errorf("fuzzyThreshold(%q) = %d，期望 %d", tt.word, got, tt.want)
+        }
+    }
+}
+
+func TestEditDistance(t *testing.T) {
+    tests := []struct {
+        a, b string
+        max  int
+        want int
+    }{
+        {"hetzner", "hetzner", 0, 0},
+        {"hetzner", "hetzer", 2, 1},
+        {"hetzner", "hertzner", 2, 1},
+        {"hetzner", "hetnzer", 2, 2},
+        {"kitten", "sitting", 3, 3},
+        {"kitten", "sitting", 2, -1},
+        {"node", "mode", 1, 1},
+        {"node", "nodes", 1, 1},
+        {"node", "no", 1, -1},
+        {"vps", "vpn", 0, -1},
+        {"ab", "ba", 2, 2},
+        {"ab", "ba", 1, -1},
+        {"", "abc", 3, 3},
+        {"abc", "", 2, -1},
+        {"优惠券", "优惠卷", 1, 1},
+        {"cloudflare", "clouflare", 2, 1},
+        {"cloudflare", "claudflair", 2, -1},
+        {"cloudflare", "claudflair", 3, 3},
+        {"cloudflare", "cloudfire", 2, 2},
+    }
+
+    for _, tt := range tests {
+        if got := editDistance(tt.a, tt.b, tt.max); got != tt.want {
+            t.Errorf("editDistance(%q, %q, %d) = %d，期望 %d", tt.a, tt.b, tt.max, got, tt.want)
+        }
+    }
+}
+
+func TestFuzzyWordMatch(t *testing.T) {
+    tests := []struct {
+        name    string
+        words   []string
+        keyword []string
+        wantHit string
+        wantOK  bool
+    }{
+        {"单个词拼写错误", []string{"cheap", "hetzer", "server"}, []string{"hetzner"}, "hetzer", true},
+        {"完全一致", []string{"hetzner", "auction"}, []string{"hetzner"}, "hetzner", true},
+        {"多个词连续出现", []string{"black", "fridy", "sale"}, []string{"black", "friday"}, "black fridy", true},
+        {"多个词不连续", []string{"black", "vps", "friday"}, []string{"black", "friday"}, "", false},
+        {"多个词顺序不同", []string{"friday", "black"}, []string{"black", "friday"}, "", false},
+        {"短词不允许误差", []string{"cheap", "vpn"}, []string{"vps"}, "", false},
+        {"含数字的词必须一致", []string{"ryzen7", "server"}, []string{"ryzen9"}, "", false},
+        {"汉字必须一致", []string{"优惠卷"}, []string{"优惠券"}, "", false},
+        {"超过阈值", []string{"linux"}, []string{"linode"}, "", false},
+        {"关键词为空", []string{"vps"}, nil, "", false},
+        {"文本为空", nil, []string{"hetzner"}, "", false},
+        {"关键词比文本长", []string{"black"}, []string{"black", "friday"}, "", false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            hit, ok := fuzzyWordMatch(tt.words, tt.keyword)
+            if hit != tt.wantHit || ok != tt.wantOK {
+                t.Errorf("fuzzyWordMatch(%q, %q) = (%q, %v)，期望 (%q, %v)", tt.words, tt.keyword, hit, ok, tt.wantHit, tt.wantOK)
+            }
+        })
+    }
+}
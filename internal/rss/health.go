@@ -0,0 +1,187 @@
+package rss
+
+import (
+    "fmt"
+    "log"
+    "sort"
+    "time"
+)
+
+const (
+    defaultAlertAfter = 6 * time.Hour // 默认连续失败6小时后提醒管理员
+    defaultMaxBackoff = 6 * time.Hour // 默认最大退避间隔
+)
+
+// AlertHandler 向管理员发送提醒的处理器
+type AlertHandler func(message string)
+
+// FeedHealth 记录订阅中单个Feed URL的健康状态。共用同一URL的订阅各自抓取，
+// 因此按订阅分别记录，与条件请求的缓存校验信息一致
+type FeedHealth struct {
+    SubscriptionID      string
+    URL                 string
+    Group               string
+    ConsecutiveFailures int       // 连续失败次数
+    FailingSince        time.Time // 本轮连续失败的开始时间
+    LastSuccess         time.Time // 最后一次成功抓取的时间
+    LastError           string    // 最后一次失败的错误信息
+    LastStatus          int       // 最后一次响应的HTTP状态码
+    NextAttempt         time.Time // 退避期间下一次允许抓取的时间
+    Alerted             bool      // 本轮失败是否已提醒过管理员
+}
+
+// Healthy 返回该URL当前是否正常
+func (h FeedHealth) Healthy() bool {
+    return h.ConsecutiveFailures == 0
+}
+
+// SetAlertHandler 设置Feed长时间失败时的提醒处理器
+func (m *Manager) SetAlertHandler(handler AlertHandler) {
+    m.alertHandler = handler
+}
+
+// Health 返回当前所有订阅URL的健康状态，按分组、URL和订阅排序
+func (m *Manager) Health() []FeedHealth {
+    m.mu.Lock()
+    feeds := m.feeds
+    m.mu.Unlock()
+
+    m.healthMu.Lock()
+    defer m.healthMu.Unlock()
+
+    result := make([]FeedHealth, 0)
+    seen := make(map[string]bool)
+    for _, feed := range feeds {
+        for _, url := range feed.URLs {
+            key := healthKey(feed, url)
+            if seen[key] {
+                continue
+            }
+            seen[key] = true
+            if h, ok := m.health[key]; ok {
+                result = append(result, *h)
+            } else {
+                result = append(result, FeedHealth{SubscriptionID: feed.ID, URL: url, Group: feed.Group})
+            }
+        }
+    }
+
+    sort.Slice(result, func(i, j int) bool {
+        if result[i].Group != result[j].Group {
+            return result[i].Group < result[j].Group
+        }
+        if result[i].URL != result[j].URL {
+            return result[i].URL < result[j].URL
+        }
+        return result[i].SubscriptionID < result[j].SubscriptionID
+    })
+    return result
+}
+
+// inBackoff 检查订阅的URL是否处于失败退避期
+func (m *Manager) inBackoff(feed *Feed, url string) (bool, time.Time) {
+    m.healthMu.Lock()
+    defer m.healthMu.Unlock()
+
+    h, ok := m.health[healthKey(feed, url)]
+    if !ok || h.NextAttempt.IsZero() {
+        return false, time.Time{}
+    }
+    // 留出一秒余量，避免轮询时间的微小误差导致错过本次检查
+    if time.Until(h.NextAttempt) > time.Second {
+        return true, h.NextAttempt
+    }
+    return false, time.Time{}
+}
+
+// recordSuccess 记录一次成功抓取
+func (m *Manager) recordSuccess(feed *Feed, url string, status int) {
+    m.healthMu.Lock()
+    h := m.healthFor(feed, url)
+    recovered := h.Alerted
+    failures := h.ConsecutiveFailures
+    h.ConsecutiveFailures = 0
+    h.FailingSince = time.Time{}
+    h.LastSuccess = time.Now()
+    h.LastError = ""
+    h.LastStatus = status
+    h.NextAttempt = time.Time{}
+    h.Alerted = false
+    m.healthMu.Unlock()
+
+    if recovered && m.alertHandler != nil {
+        m.alertHandler(fmt.Sprintf("✅ 订阅 [%s] 已恢复：[%s] %s\n此前连续失败 %d 次", feed.ID, feed.Group, url, failures))
+    }
+}
+
+// recordFailure 记录一次失败抓取，计算退避时间，并在失败持续过久时提醒管理员
+func (m *Manager) recordFailure(feed *Feed, url string, status int, err error) {
+    now := time.Now()
+
+    m.healthMu.Lock()
+    h := m.healthFor(feed, url)
+    h.ConsecutiveFailures++
+    if h.FailingSince.IsZero() {
+        h.FailingSince = now
+    }
+    h.LastError = err.Error()
+    h.LastStatus = status
+
+    // 第一次失败按正常间隔重试，之后每次失败退避时间翻倍
+    if h.ConsecutiveFailures > 1 {
+        backoff := m.backoffFor(feed.Interval, h.ConsecutiveFailures)
+        h.NextAttempt = now.Add(backoff)
+        log.Printf("⏳ Feed连续失败 %d 次，%s 后重试: %s", h.ConsecutiveFailures, backoff, url)
+    }
+
+    alertAfter := m.getOptions().AlertAfter
+    if alertAfter <= 0 {
+        alertAfter = defaultAlertAfter
+    }
+    shouldAlert := !h.Alerted && now.Sub(h.FailingSince) >= alertAfter
+    if shouldAlert {
+        h.Alerted = true
+    }
+    snapshot := *h
+    m.healthMu.Unlock()
+
+    if shouldAlert && m.alertHandler != nil {
+        m.alertHandler(fmt.Sprintf("⚠️ 订阅 [%s] 长时间抓取失败：[%s] %s\n连续失败 %d 次，已持续 %s\n最后状态码: %d\n最后错误: %s",
+            feed.ID, snapshot.Group, url, snapshot.ConsecutiveFailures,
+            now.Sub(snapshot.FailingSince).Round(time.Minute), snapshot.LastStatus, snapshot.LastError))
+    }
+}
+
+// backoffFor 按连续失败次数计算指数退避时间
+func (m *Manager) backoffFor(interval time.Duration, failures int) time.Duration {
+    maxBackoff := m.getOptions().MaxBackoff
+    if maxBackoff <= 0 {
+        maxBackoff = defaultMaxBackoff
+    }
+
+    backoff := interval
+    for i := 1; i < failures && backoff < maxBackoff; i++ {
+        backoff *= 2
+    }
+    if backoff > maxBackoff {
+        backoff = maxBackoff
+    }
+    return backoff
+}
+
+// healthKey 返回订阅在指定URL上的健康记录键
+func healthKey(feed *Feed, url string) string {
+    return feed.ID + "|" + url
+}
+
+// healthFor 获取订阅在URL上的健康记录，不存在时创建，调用方需持有 healthMu
+func (m *Manager) healthFor(feed *Feed, url string) *FeedHealth {
+    key := healthKey(feed, url)
+    h, ok := m.health[key]
+    if !ok {
+        h = &FeedHealth{SubscriptionID: feed.ID, URL: url}
+        m.health[key] = h
+    }
+    h.Group = feed.Group
+    return h
+}
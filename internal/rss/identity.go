@@ -0,0 +1,74 @@
+package rss
+
+import (
+    "crypto/sha1"
+    "encoding/hex"
+    "time"
+
+    "github.com/mmcdole/gofeed"
+    "rss2tg/internal/urlutil"
+)
+
+// 文章去重标识的生成方式
+const (
+    DedupByLink           = "link"            // 原始链接（默认）
+    DedupByGUID           = "guid"            // 文章 GUID
+    DedupByNormalizedLink = "normalized_link" // 去除追踪参数后的规范化链接
+    DedupByTitleDate      = "title_date"      // 标题与发布时间的哈希
+)
+
+// itemKey 按订阅配置的去重方式生成文章的唯一标识，
+// 所选字段为空时依次回退到链接、GUID 和标题哈希
+func itemKey(item *gofeed.Item, feed *Feed) string {
+    switch feed.DedupKey {
+    case DedupByGUID:
+        if item.GUID != "" {
+            return item.GUID
+        }
+    case DedupByNormalizedLink:
+        if item.Link != "" {
+            return urlutil.Normalize(item.Link, feed.StripParams)
+        }
+    case DedupByTitleDate:
+        return titleDateKey(item)
+    }
+
+    if item.Link != "" {
+        return item.Link
+    }
+    if item.GUID != "" {
+        return item.GUID
+    }
+    return titleDateKey(item)
+}
+
+// titleDateKey 使用标准化标题和发布时间生成哈希标识
+func titleDateKey(item *gofeed.Item) string {
+    date := ""
+    if t := itemSortTime(item); !t.IsZero() {
+        date = t.UTC().Format(time.RFC3339)
+    }
+    sum := sha1.Sum([]byte(normalizeText(item.Title) + "|" + date))
+    return "hash:" + hex.EncodeToString(sum[:])[:16]
+}
+
+// sentKey 返回文章在订阅中的已发送记录标识。记录按订阅区分，共用同一URL的订阅
+// 可能推送到不同的目标，各自推送匹配的文章
+func sentKey(feed *Feed, key string) string {
+    return feed.ID + "|" + key
+}
+
+// wasSent 检查文章是否已由订阅发送过。除当前去重标识外，还检查原始链接和规范化链接，
+// 以便修改 dedup_key 后不会重复推送；同时兼容不区分订阅的旧记录
+func (m *Manager) wasSent(feed *Feed, item *gofeed.Item, key string) bool {
+    keys := []string{key}
+    if item.Link != "" {
+        keys = append(keys, item.Link, urlutil.Normalize(item.Link, feed.StripParams))
+    }
+    for _, k := range keys {
+        if m.db.WasSent(sentKey(feed, k)) || m.db.WasSent(k) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,158 @@
+package rss
+
+import (
+    "strings"
+    "time"
+
+    "github.com/mmcdole/gofeed"
+    ext "github.com/mmcdole/gofeed/extensions"
+    "rss2tg/internal/model"
+)
+
+// newItem 将 gofeed 解析出的文章转换为下游使用的文章模型，
+// RSS、Atom 与 JSON Feed 经 gofeed 转换后字段一致
+func newItem(feed *Feed, parsedFeed *gofeed.Feed, item *gofeed.Item, pubDate time.Time) *model.Item {
+    result := &model.Item{
+        SubscriptionID: feed.ID,
+        Group:          feed.Group,
+        Route:          feed.Route,
+        Title:          item.Title,
+        Link:           item.Link,
+        GUID:           item.GUID,
+        Description:    item.Description,
+        Content:        item.Content,
+        Author:         itemAuthor(item),
+        Categories:     item.Categories,
+        Published:      pubDate,
+        Extensions:     flattenExtensions(item.Extensions, item.Custom),
+    }
+    if parsedFeed != nil {
+        result.FeedTitle = parsedFeed.Title
+    }
+    if item.UpdatedParsed != nil {
+        result.Updated = *item.UpdatedParsed
+    }
+    for _, enclosure := range item.Enclosures {
+        if enclosure == nil || enclosure.URL == "" {
+            continue
+        }
+        result.Enclosures = append(result.Enclosures, model.Enclosure{
+            URL:    enclosure.URL,
+            Type:   enclosure.Type,
+            Length: enclosure.Length,
+        })
+    }
+    result.Image = itemImage(item)
+    return result
+}
+
+// itemAuthor 返回文章作者，多个作者以逗号分隔
+func itemAuthor(item *gofeed.Item) string {
+    var names []string
+    for _, person := range item.Authors {
+        if person == nil {
+            continue
+        }
+        name := person.Name
+        if name == "" {
+            name = person.Email
+        }
+        if name != "" && !contains(names, name) {
+            names = append(names, name)
+        }
+    }
+    if len(names) == 0 && item.Author != nil {
+        if item.Author.Name != "" {
+            names = append(names, item.Author.Name)
+        } else if item.Author.Email != "" {
+            names = append(names, item.Author.Email)
+        }
+    }
+    if len(names) == 0 && item.DublinCoreExt != nil {
+        names = append(names, item.DublinCoreExt.Creator...)
+    }
+    return strings.Join(names, ", ")
+}
+
+// itemImage 确定文章的封面图：依次使用文章图片、media 扩展中的缩略图或图片和图片附件
+func itemImage(item *gofeed.Item) string {
+    if item.Image != nil && item.Image.URL != "" {
+        return item.Image.URL
+    }
+    if url := mediaImage(item.Extensions["media"]); url != "" {
+        return url
+    }
+    for _, enclosure := range item.Enclosures {
+        if enclosure != nil && strings.HasPrefix(strings.ToLower(enclosure.Type), "image/") {
+            return enclosure.URL
+        }
+    }
+    return ""
+}
+
+// mediaImage 从 Media RSS 扩展中查找图片地址，包括 media:group 内的元素
+func mediaImage(elements map[string][]ext.Extension) string {
+    for _, thumbnail := range elements["thumbnail"] {
+        if url := thumbnail.Attrs["url"]; url != "" {
+            return url
+        }
+    }
+    for _, content := range elements["content"] {
+        url := content.Attrs["url"]
+        isImage := content.Attrs["medium"] == "image" ||
+            strings.HasPrefix(strings.ToLower(content.Attrs["type"]), "image/")
+        if url != "" && isImage {
+            return url
+        }
+        if url := mediaImage(content.Children); url != "" {
+            return url
+        }
+    }
+    for _, group := range elements["group"] {
+        if url := mediaImage(group.Children); url != "" {
+            return url
+        }
+    }
+    return ""
+}
+
+// flattenExtensions 将扩展元素展开为 "前缀:名称" 到取值列表的映射，
+// 元素没有文本内容时使用其 url 属性（如 media:thumbnail）
+func flattenExtensions(extensions ext.Extensions, custom map[string]string) map[string][]string {
+    result := make(map[string][]string)
+    for prefix, elements := range extensions {
+        for name, values := range elements {
+            key := prefix + ":" + name
+            for _, value := range values {
+                flattenExtension(result, key, value)
+            }
+        }
+    }
+    for name, value := range custom {
+        if value != "" {
+            result[name] = append(result[name], value)
+        }
+    }
+    if len(result) == 0 {
+        return nil
+    }
+    return result
+}
+
+// flattenExtension 展开单个扩展元素及其子元素，例如 media:group 下的 media:content
+func flattenExtension(result map[string][]string, key string, element ext.Extension) {
+    value := strings.TrimSpace(element.Value)
+    if value == "" {
+        value = element.Attrs["url"]
+    }
+    if value != "" {
+        result[key] = append(result[key], value)
+    }
+
+    prefix := key[:strings.Index(key, ":")+1]
+    for name, children := range element.Children {
+        for _, child := range children {
+            flattenExtension(result, prefix+name, child)
+        }
+    }
+}
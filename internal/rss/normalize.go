@@ -0,0 +1,191 @@
+package rss
+
+import (
+    "sort"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "rss2tg/internal/textfold"
+)
+
+// NormalizeOptions 定义关键词匹配前的文本标准化规则
+type NormalizeOptions struct {
+    Synonyms                map[string]string // 同义词表：别名 -> 标准词，匹配前统一替换为标准词，含符号的别名（如 c++）在去除标点前替换
+    FoldWidth               bool              // 是否将全角字符转换为半角
+    TraditionalToSimplified bool              // 是否将繁体字转换为简体字
+}
+
+// synonym 一条标准化后的同义词
+type synonym struct {
+    alias     string
+    canonical string
+}
+
+// normalizer 按配置标准化文本，关键词和文章使用同一个 normalizer 才能正确比较
+type normalizer struct {
+    options         NormalizeOptions
+    keepPunctuation bool      // 保留标点，作为独立的词参与匹配
+    replacements    []synonym // 含符号的别名，在处理标点之前替换，避免被拆开；按别名长度降序
+    synonyms        []synonym // 按别名长度降序，优先替换较长的别名
+}
+
+// normalizers 去除标点和保留标点两种标准化规则，订阅按自己的设置选用
+type normalizers struct {
+    strip *normalizer
+    keep  *normalizer
+}
+
+func newNormalizers(options NormalizeOptions) normalizers {
+    return normalizers{
+        strip: newNormalizer(options, false),
+        keep:  newNormalizer(options, true),
+    }
+}
+
+// forFeed 返回订阅使用的标准化规则
+func (n normalizers) forFeed(feed *Feed) *normalizer {
+    if feed.KeepPunctuation {
+        return n.keep
+    }
+    return n.strip
+}
+
+func newNormalizer(options NormalizeOptions, keepPunctuation bool) *normalizer {
+    n := &normalizer{options: options, keepPunctuation: keepPunctuation}
+    // 含符号的别名按原文替换，标准词在替换后随文章一起处理标点
+    for alias, canonical := range options.Synonyms {
+        alias = n.foldCase(alias)
+        if canonical = n.foldCase(canonical); hasSymbol(alias) && alias != canonical {
+            n.replacements = append(n.replacements, synonym{alias: alias, canonical: canonical})
+        }
+    }
+    sortSynonyms(n.replacements)
+
+    for alias, canonical := range options.Synonyms {
+        if hasSymbol(n.foldCase(alias)) {
+            continue
+        }
+        // 别名和标准词与文章经过相同的处理，才能在标准化后的文本中找到
+        alias = n.fold(alias)
+        canonical = n.fold(canonical)
+        if alias == "" || alias == canonical {
+            continue
+        }
+        n.synonyms = append(n.synonyms, synonym{alias: alias, canonical: canonical})
+    }
+    sortSynonyms(n.synonyms)
+    return n
+}
+
+// sortSynonyms 按别名长度降序排列，长度相同时按别名排序，保证替换顺序稳定
+func sortSynonyms(synonyms []synonym) {
+    sort.Slice(synonyms, func(i, j int) bool {
+        if len(synonyms[i].alias) != len(synonyms[j].alias) {
+            return len(synonyms[i].alias) > len(synonyms[j].alias)
+        }
+        return synonyms[i].alias < synonyms[j].alias
+    })
+}
+
+// hasSymbol 判断文本是否含有字母、数字和空白以外的字符，这些字符会在处理标点时被去除或拆开
+func hasSymbol(text string) bool {
+    for _, ch := range text {
+        if !unicode.IsLetter(ch) && !unicode.IsNumber(ch) && !unicode.IsSpace(ch) {
+            return true
+        }
+    }
+    return false
+}
+
+// normalize 标准化文本：字符折叠、替换含符号的别名、处理标点和空白，最后替换同义词
+func (n *normalizer) normalize(text string) string {
+    return n.replaceSynonyms(n.fold(text))
+}
+
+// foldCase 全角转半角、繁体转简体并转换为小写
+func (n *normalizer) foldCase(text string) string {
+    if n.options.FoldWidth {
+        text = textfold.Width(text)
+    }
+    if n.options.TraditionalToSimplified {
+        text = textfold.ToSimplified(text)
+    }
+    return strings.ToLower(strings.TrimSpace(text))
+}
+
+// fold 执行同义词替换之前的所有步骤
+func (n *normalizer) fold(text string) string {
+    // 1-2. 全角转半角、繁体转简体，转换为小写
+    text = n.foldCase(text)
+
+    // 3. 替换含符号的别名，如 c++、c#、.net
+    for _, r := range n.replacements {
+        text = strings.ReplaceAll(text, r.alias, r.canonical)
+    }
+
+    // 4. 清理特殊字符，保留字母、数字和空格；保留标点时将其前后断开作为独立的词
+    var result strings.Builder
+    for _, ch := range text {
+        switch {
+        case unicode.IsLetter(ch) || unicode.IsNumber(ch) || unicode.IsSpace(ch):
+            result.WriteRune(ch)
+        case n.keepPunctuation && !unicode.IsControl(ch):
+            result.WriteRune(' ')
+            result.WriteRune(ch)
+            result.WriteRune(' ')
+        default:
+            // 用空格替换特殊字符
+            result.WriteRune(' ')
+        }
+    }
+
+    // 5. 规范化空白字符
+    return strings.Join(strings.Fields(result.String()), " ")
+}
+
+// replaceSynonyms 将别名替换为标准词。字母和数字组成的别名只在词边界处替换，
+// 例如别名 hz 不会替换 mhz 中的 hz；中文别名不要求边界
+func (n *normalizer) replaceSynonyms(text string) string {
+    if len(n.synonyms) == 0 {
+        return text
+    }
+    var result strings.Builder
+    prev := rune(-1)
+    for i := 0; i < len(text); {
+        replaced := false
+        for _, s := range n.synonyms {
+            if !strings.HasPrefix(text[i:], s.alias) {
+                continue
+            }
+            first, _ := utf8.DecodeRuneInString(s.alias)
+            last, _ := utf8.DecodeLastRuneInString(s.alias)
+            next, _ := utf8.DecodeRuneInString(text[i+len(s.alias):])
+            if isWordBoundary(prev, first) && isWordBoundary(last, next) {
+                result.WriteString(s.canonical)
+                i += len(s.alias)
+                prev = last
+                replaced = true
+                break
+            }
+        }
+        if replaced {
+            continue
+        }
+        ch, size := utf8.DecodeRuneInString(text[i:])
+        result.WriteRune(ch)
+        i += size
+        prev = ch
+    }
+    return result.String()
+}
+
+// isWordBoundary 判断两个相邻字符之间是否为词边界，
+// 只有两侧都是非汉字的字母或数字时才不是边界
+func isWordBoundary(left, right rune) bool {
+    return !isWordRune(left) || !isWordRune(right)
+}
+
+func isWordRune(ch rune) bool {
+    return (unicode.IsLetter(ch) || unicode.IsNumber(ch)) && !unicode.Is(unicode.Han, ch)
+}
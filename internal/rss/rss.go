@@ -1,50 +1,116 @@
 package rss
 
 import (
+    "fmt"
     "log"
     "net/http"
+    "reflect"
+    "regexp"
+    "sort"
     "strings"
     "sync"
     "time"
-    "unicode"
 
     "github.com/mmcdole/gofeed"
+    "rss2tg/internal/filter"
+    "rss2tg/internal/model"
+    "rss2tg/internal/segment"
     "rss2tg/internal/storage"
 )
  
-type MessageHandler func(title, url, group string, pubDate time.Time, matchedKeywords []string) error
+// MessageHandler 推送匹配文章的处理器
+type MessageHandler func(item *model.Item) error
 
 type Manager struct {
     feeds          []*Feed
     db             *storage.Storage
     messageHandler MessageHandler
+    alertHandler   AlertHandler
+    options        Options
+    optionsMu      sync.Mutex
+    health         map[string]*FeedHealth
+    healthMu       sync.Mutex
+    duplicates     duplicateIndex
+    scheduler      *scheduler
+    blocklist      []keywordRule            // 由 Options.Blocklist 编译出的全局屏蔽规则
+    keywordSets    map[string][]keywordRule // 由 Options.KeywordSets 编译出的关键词组规则
+    segmenter      *segment.Segmenter       // 分词器，用户词典包含所有订阅的关键词
+    normalizers    normalizers              // 由 Options.Normalize 生成的文本标准化规则
     mu             sync.Mutex
 }
 
+// Options 定义管理器的全局选项
+type Options struct {
+    AlertAfter          time.Duration       // URL连续失败多久后提醒管理员
+    MaxBackoff          time.Duration       // 失败退避的最大间隔
+    CrossFeedDedup      bool                // 是否抑制不同订阅中的重复文章
+    DuplicateWindow     time.Duration       // 跨订阅去重的时间窗口
+    DuplicateSimilarity float64             // 判定为重复的标题相似度阈值（0-1）
+    MaxConcurrency      int                 // 全局最大并发抓取数
+    PerHostConcurrency  int                 // 同一主机最大并发抓取数
+    StartJitter         time.Duration       // 订阅开始调度时的随机延迟上限
+    Blocklist           []string            // 全局屏蔽词，对所有订阅生效
+    Normalize           NormalizeOptions    // 关键词匹配前的文本标准化规则
+    KeywordSets         map[string][]string // 命名的关键词组，订阅按名称引用
+}
+
 type Feed struct {
-    URLs            []string
-    Interval        time.Duration
-    Keywords        []string
-    Group           string
-    AllowPartMatch  bool      // 是否允许部分匹配
-    Enabled         bool      // 是否启用此订阅
-    ticker          *time.Ticker
-    stopChan        chan struct{}
+    ID                string
+    URLs              []string
+    Interval          time.Duration
+    Keywords          []string
+    Group             string
+    AllowPartMatch    bool          // 是否允许部分匹配
+    Enabled           bool          // 是否启用此订阅
+    PrimeOnFirstFetch bool          // 首次抓取时只标记已有文章，不推送
+    PrimeSendNewest   int           // 首次抓取时仍推送的最新文章数
+    DedupKey          string        // 文章去重标识的生成方式
+    StripParams       []string      // 规范化链接时额外去除的参数
+    MaxAge            time.Duration // 文章最大时效，超过则不推送
+    ExcludeKeywords   []string      // 排除词，命中则不推送
+    Regex             []RegexRule   // 命名的正则规则
+    MatchFields       []string      // 关键词默认匹配的字段
+    KeepPunctuation   bool          // 匹配时保留标点，不将其替换为空格
+    FuzzyMatch        bool          // 精确匹配失败时按编辑距离模糊匹配关键词
+    MinScore          float64       // 分数阈值，总分超过该值才推送，0 表示总分大于0即推送
+    KeywordSets       []string      // 引用的关键词组名称，匹配时按名称查找最新的关键词组
+    Route             model.Route   // 推送目标，为空时使用全局设置
+    rules             []keywordRule // 由 Keywords 和 Regex 编译出的匹配规则
+    excludeRules      []keywordRule // 由 ExcludeKeywords 编译出的排除规则
+    config            Config        // 创建时的配置，用于判断订阅是否变化
 }
 
 type Config struct {
-    URLs            []string
-    Interval        int
-    Keywords        []string
-    Group           string
-    AllowPartMatch  bool      // 是否允许部分匹配
-    Enabled         bool      // 是否启用此订阅
+    ID                string      // 订阅的稳定标识
+    URLs              []string
+    Interval          int
+    Keywords          []string
+    Group             string
+    AllowPartMatch    bool        // 是否允许部分匹配
+    Enabled           bool        // 是否启用此订阅
+    PrimeOnFirstFetch bool        // 首次抓取时只标记已有文章，不推送
+    PrimeSendNewest   int         // 首次抓取时仍推送的最新文章数
+    DedupKey          string      // 文章去重标识的生成方式
+    StripParams       []string    // 规范化链接时额外去除的参数
+    MaxAgeHours       int         // 文章最大时效（小时），0表示不限制
+    ExcludeKeywords   []string    // 排除词，命中则不推送
+    Regex             []RegexRule // 命名的正则规则
+    MatchFields       []string    // 关键词默认匹配的字段，为空时匹配标题和描述
+    KeepPunctuation   bool        // 匹配时保留标点，不将其替换为空格
+    FuzzyMatch        bool        // 精确匹配失败时按编辑距离模糊匹配关键词
+    MinScore          float64     // 分数阈值，总分超过该值才推送，0 表示总分大于0即推送
+    KeywordSets       []string    // 引用的关键词组名称
+    Route             model.Route // 推送目标，为空时使用全局设置
 }
 
 func NewManager(configs []Config, db *storage.Storage) *Manager {
     manager := &Manager{
-        db: db,
+        db:          db,
+        health:      make(map[string]*FeedHealth),
+        segmenter:   segment.New(nil),
+        normalizers: newNormalizers(NormalizeOptions{}),
     }
+    manager.scheduler = newScheduler(manager.getOptions, manager.runJob)
     manager.UpdateFeeds(configs)
     return manager
 }
@@ -53,72 +119,199 @@ func (m *Manager) SetMessageHandler(handler MessageHandler) {
     m.messageHandler = handler
 }
 
+// SetOptions 更新管理器的全局选项
+func (m *Manager) SetOptions(options Options) {
+    blocklist := compileKeywords("全局屏蔽词", options.Blocklist)
+    keywordSets := make(map[string][]keywordRule, len(options.KeywordSets))
+    for name, keywords := range options.KeywordSets {
+        keywordSets[name] = compileKeywords("关键词组 ["+name+"]", keywords)
+    }
+    normalizers := newNormalizers(options.Normalize)
+
+    m.mu.Lock()
+    segmenter := newSegmenter(m.feeds, globalKeywords(options), normalizers.strip)
+    m.mu.Unlock()
+
+    m.optionsMu.Lock()
+    m.options = options
+    m.blocklist = blocklist
+    m.keywordSets = keywordSets
+    m.segmenter = segmenter
+    m.normalizers = normalizers
+    m.optionsMu.Unlock()
+
+    // 并发限制可能已放宽，唤醒调度器
+    m.scheduler.signal()
+}
+
+// getOptions 返回当前的全局选项
+func (m *Manager) getOptions() Options {
+    m.optionsMu.Lock()
+    defer m.optionsMu.Unlock()
+    return m.options
+}
+
+// getSegmenter 返回当前的分词器
+func (m *Manager) getSegmenter() *segment.Segmenter {
+    m.optionsMu.Lock()
+    defer m.optionsMu.Unlock()
+    return m.segmenter
+}
+
+// getNormalizers 返回当前的文本标准化规则
+func (m *Manager) getNormalizers() normalizers {
+    m.optionsMu.Lock()
+    defer m.optionsMu.Unlock()
+    return m.normalizers
+}
+
+// getKeywordSetRules 返回订阅引用的关键词组编译后的规则，不存在的关键词组被忽略
+func (m *Manager) getKeywordSetRules(feed *Feed) []keywordRule {
+    m.optionsMu.Lock()
+    defer m.optionsMu.Unlock()
+    var rules []keywordRule
+    for _, name := range feed.KeywordSets {
+        rules = append(rules, m.keywordSets[name]...)
+    }
+    return rules
+}
+
+// getBlocklist 返回编译后的全局屏蔽规则
+func (m *Manager) getBlocklist() []keywordRule {
+    m.optionsMu.Lock()
+    defer m.optionsMu.Unlock()
+    return m.blocklist
+}
+
+// UpdateFeeds 按订阅ID对比新旧配置，只重新调度新增、删除和修改过的订阅，
+// 未变化的订阅保留原有的调度时间和状态
 func (m *Manager) UpdateFeeds(configs []Config) {
     m.mu.Lock()
     defer m.mu.Unlock()
 
-    // 停止所有现有的feed轮询器
+    existing := make(map[string]*Feed, len(m.feeds))
     for _, feed := range m.feeds {
-        if feed.stopChan != nil {
-            close(feed.stopChan)
-        }
+        existing[feed.ID] = feed
     }
 
-    // 创建新的feeds
-    m.feeds = make([]*Feed, len(configs))
-    for i, config := range configs {
-        m.feeds[i] = &Feed{
-            URLs:           config.URLs,
-            Interval:       time.Duration(config.Interval) * time.Second,
-            Keywords:       config.Keywords,
-            Group:          config.Group,
-            AllowPartMatch: config.AllowPartMatch,  // 添加部分匹配配置
-            Enabled:        config.Enabled,         // 添加启用状态配置
-            stopChan:       make(chan struct{}),
+    added, modified, unchanged := 0, 0, 0
+    feeds := make([]*Feed, 0, len(configs))
+    for _, config := range configs {
+        id := subscriptionID(config)
+        if old, ok := existing[id]; ok {
+            delete(existing, id)
+            if reflect.DeepEqual(old.config, config) {
+                feeds = append(feeds, old)
+                unchanged++
+                continue
+            }
+            m.scheduler.remove(old)
+            modified++
+        } else {
+            added++
         }
-    }
 
-    // 启动新的feed轮询器（仅启用的订阅）
-    for _, feed := range m.feeds {
+        feed := newFeed(id, config)
+        feeds = append(feeds, feed)
         if feed.Enabled {
-            go m.pollFeed(feed)
+            m.scheduler.add(feed)
         } else {
-            log.Printf("订阅已禁用，跳过启动轮询器: %v", feed.URLs)
+            log.Printf("订阅已禁用，跳过调度: %v", feed.URLs)
         }
     }
+
+    // 剩余的旧订阅已从配置中删除
+    for _, old := range existing {
+        m.scheduler.remove(old)
+    }
+
+    m.feeds = feeds
+
+    // 关键词可能已变化，重建分词器的用户词典
+    segmenter := newSegmenter(feeds, globalKeywords(m.getOptions()), m.getNormalizers().strip)
+    m.optionsMu.Lock()
+    m.segmenter = segmenter
+    m.optionsMu.Unlock()
+
+    log.Printf("订阅已同步: 新增 %d, 修改 %d, 删除 %d, 未变化 %d", added, modified, len(existing), unchanged)
 }
 
+// subscriptionID 返回订阅的稳定标识，未配置ID时使用URL列表代替
+func subscriptionID(config Config) string {
+    if config.ID != "" {
+        return config.ID
+    }
+    return "urls:" + strings.Join(config.URLs, ",")
+}
+
+// newFeed 根据订阅配置创建Feed，检查间隔小于下限时按下限调度
+func newFeed(id string, config Config) *Feed {
+    interval := time.Duration(config.Interval) * time.Second
+    if interval < minInterval {
+        if config.Interval != 0 {
+            log.Printf("订阅 [%s] 的检查间隔 %d 秒过小，按 %d 秒调度", id, config.Interval, int(minInterval/time.Second))
+        }
+        interval = minInterval
+    }
+    return &Feed{
+        ID:                id,
+        URLs:              config.URLs,
+        Interval:          interval,
+        Keywords:          config.Keywords,
+        Group:             config.Group,
+        AllowPartMatch:    config.AllowPartMatch,  // 添加部分匹配配置
+        Enabled:           config.Enabled,         // 添加启用状态配置
+        PrimeOnFirstFetch: config.PrimeOnFirstFetch,
+        PrimeSendNewest:   config.PrimeSendNewest,
+        DedupKey:          config.DedupKey,
+        StripParams:       config.StripParams,
+        MaxAge:            time.Duration(config.MaxAgeHours) * time.Hour,
+        ExcludeKeywords:   config.ExcludeKeywords,
+        Regex:             config.Regex,
+        MatchFields:       matchFields(config.MatchFields),
+        KeepPunctuation:   config.KeepPunctuation,
+        FuzzyMatch:        config.FuzzyMatch,
+        MinScore:          config.MinScore,
+        KeywordSets:       config.KeywordSets,
+        Route:             config.Route,
+        rules:             append(compileKeywords("订阅 ["+id+"]", config.Keywords), compileRegexRules("订阅 ["+id+"]", config.Regex)...),
+        excludeRules:      compileKeywords("订阅 ["+id+"] 的排除词", config.ExcludeKeywords),
+        config:            config,
+    }
+}
+
+// Start 启动调度循环，按各URL的到期时间抓取Feed，不会返回
 func (m *Manager) Start() {
     log.Println("RSS管理器已启动")
+    m.scheduler.loop()
 }
 
-func (m *Manager) pollFeed(feed *Feed) {
-    feed.ticker = time.NewTicker(feed.Interval)
-    defer feed.ticker.Stop()
+// runJob 由调度器调用，检查单个URL并返回下一次检查时间，
+// 处于失败退避期时推迟到退避结束
+func (m *Manager) runJob(feed *Feed, url string) time.Time {
+    m.checkFeed(feed, url)
 
-    for {
-        select {
-        case <-feed.ticker.C:
-            if !feed.Enabled {
-                log.Printf("订阅已禁用，跳过轮询: %v", feed.URLs)
-                continue
-            }
-            for _, url := range feed.URLs {
-                m.checkFeed(feed, url)
-            }
-        case <-feed.stopChan:
-            log.Printf("停止feed轮询器: %v", feed.URLs)
-            return
-        }
+    next := time.Now().Add(feed.Interval)
+    if backoff, until := m.inBackoff(feed, url); backoff && until.After(next) {
+        next = until
     }
+    return next
 }
 
-func (m *Manager) checkFeed(feed *Feed, url string) {
-    // 开始检查Feed的日志
-    log.Printf("🔍 开始检查Feed: %s", url)
-    
+// fetchResult 一次Feed抓取的结果
+type fetchResult struct {
+    feed         *gofeed.Feed
+    statusCode   int
+    notModified  bool
+    etag         string
+    lastModified string
+}
+
+// fetchFeed 抓取并解析Feed，返回结果中包含HTTP状态码；
+// subscriptionID 不为空时携带该订阅上次保存的缓存校验信息发起条件请求
+func (m *Manager) fetchFeed(url, subscriptionID string) (*fetchResult, error) {
     fp := gofeed.NewParser()
-    
+
     // 创建自定义的 HTTP 客户端
     client := &http.Client{
         Timeout: 30 * time.Second,
@@ -127,120 +320,284 @@ func (m *Manager) checkFeed(feed *Feed, url string) {
     // 创建自定义的请求
     req, err := http.NewRequest("GET", url, nil)
     if err != nil {
-        log.Printf("❌ 创建请求失败 %s: %v", url, err)
-        return
+        return nil, fmt.Errorf("创建请求失败: %v", err)
     }
     
     // 添加浏览器标识和其他必要的头信息
     req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-    req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+    req.Header.Set("Accept", "application/rss+xml,application/atom+xml,application/feed+json,application/json;q=0.9,text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
     req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
     req.Header.Set("Connection", "keep-alive")
     req.Header.Set("Upgrade-Insecure-Requests", "1")
-    
-    // 使用自定义客户端解析 Feed
-    fp.Client = client
-    parsedFeed, err := fp.ParseURL(url)
+
+    // 携带上次保存的缓存校验信息，发起条件请求
+    if subscriptionID != "" {
+        etag, lastModified := m.db.GetValidators(subscriptionID, url)
+        if etag != "" {
+            req.Header.Set("If-None-Match", etag)
+        }
+        if lastModified != "" {
+            req.Header.Set("If-Modified-Since", lastModified)
+        }
+    }
+
+    resp, err := client.Do(req)
     if err != nil {
+        return nil, fmt.Errorf("请求失败: %v", err)
+    }
+    defer resp.Body.Close()
+
+    result := &fetchResult{
+        statusCode:   resp.StatusCode,
+        etag:         resp.Header.Get("ETag"),
+        lastModified: resp.Header.Get("Last-Modified"),
+    }
+
+    if resp.StatusCode == http.StatusNotModified {
+        result.notModified = true
+        return result, nil
+    }
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return result, fmt.Errorf("HTTP状态码 %d", resp.StatusCode)
+    }
+
+    // 使用响应内容解析 Feed
+    result.feed, err = fp.Parse(resp.Body)
+    if err != nil {
+        return result, fmt.Errorf("解析失败: %v", err)
+    }
+    return result, nil
+}
+
+func (m *Manager) checkFeed(feed *Feed, url string) {
+    // 处于失败退避期的URL暂不检查
+    if backoff, next := m.inBackoff(feed, url); backoff {
+        log.Printf("⏳ Feed处于失败退避期，跳过检查: %s (下次检查: %s)", url, next.Format("15:04:05"))
+        return
+    }
+
+    // 开始检查Feed的日志
+    log.Printf("🔍 开始检查Feed: %s", url)
+
+    result, err := m.fetchFeed(url, feed.ID)
+    if err != nil {
+        status := 0
+        if result != nil {
+            status = result.statusCode
+        }
+        m.recordFailure(feed, url, status, err)
         log.Printf("❌ 解析Feed失败 %s: %v", url, err)
         return
     }
+    m.recordSuccess(feed, url, result.statusCode)
+
+    if result.notModified {
+        log.Printf("📝 Feed检查完成: %s - 内容未变化 (304)", url)
+        return
+    }
+    parsedFeed := result.feed
+
+    // 仅当本次所有匹配文章都发送成功时才保存校验信息，
+    // 否则下次请求会因 304 跳过发送失败的文章
+    sendFailed := false
+    defer func() {
+        if sendFailed {
+            return
+        }
+        if err := m.db.SaveValidators(feed.ID, url, result.etag, result.lastModified); err != nil {
+            log.Printf("❌ 保存Feed缓存信息失败 %s: %v", url, err)
+        }
+    }()
 
     // 统计信息
     totalArticles := len(parsedFeed.Items)
     newArticles := 0
     matchedArticles := 0
+    staleArticles := 0
+    excludedArticles := 0
     
     log.Printf("📊 Feed包含 %d 篇文章", totalArticles)
     
+    // 记录订阅已成功抓取过该URL，订阅首次抓取时按需只标记不推送。
+    // 按订阅判断，新订阅即使与其他订阅共用URL也会先标记已有文章
+    firstFetch := !m.db.HasFetched(feed.ID, url)
+    if err := m.db.MarkFetched(feed.ID, url); err != nil {
+        log.Printf("❌ 保存Feed抓取记录失败 %s: %v", url, err)
+    }
+
     if totalArticles == 0 {
         log.Printf("📝 Feed检查完成: %s - 无新文章", url)
         return
     }
 
+    var primed map[*gofeed.Item]bool
+    if firstFetch && feed.PrimeOnFirstFetch {
+        primed = m.primeItems(feed, url, parsedFeed.Items)
+    }
+
     for _, item := range parsedFeed.Items {
-        matchedKeywords := m.matchKeywords(item, feed)
-        
-        // 如果文章未曾发送过，说明是新文章
-        if !m.db.WasSent(item.Link) {
-            newArticles++
+        if primed[item] {
+            continue
+        }
+
+        key := itemKey(item, feed)
+        if m.wasSent(feed, item, key) {
+            continue
+        }
+        sent := sentKey(feed, key)
+
+        // 忽略超过最大时效的旧文章
+        pubDate := m.resolveItemDate(item, key)
+        if feed.MaxAge > 0 && time.Since(pubDate) > feed.MaxAge {
+            staleArticles++
+            continue
         }
+
+        // 文章未曾发送过，说明是新文章
+        newArticles++
+        entry := newItem(feed, parsedFeed, item, pubDate)
+        result := m.matchKeywords(entry, feed)
         
         // 修改判断逻辑：如果没有配置关键词或者匹配到了关键词，就发送消息
-        if len(matchedKeywords) > 0 {
+        if result.matched {
             matchedArticles++
             
             // 根据URL获取简短的RSS源名称用于日志
             var logMessage string
             var keywordInfo string
             
-            if matchedKeywords[0] == "__NO_KEYWORDS__" {
+            if len(result.keywords) == 0 {
                 logMessage = "✅ 发现新文章"
                 keywordInfo = "无关键词过滤"
-                // 使用空数组，这样在消息中就不会显示关键词
-                matchedKeywords = []string{}
             } else {
                 logMessage = "🎯 发现匹配文章"
-                keywordInfo = strings.Join(matchedKeywords, ", ")
+                keywordInfo = strings.Join(result.keywords, ", ")
+            }
+            
+            if len(result.fuzzyHits) > 0 {
+                logMessage = "🌀 发现模糊匹配文章"
+                keywordInfo += " | 模糊匹配: " + strings.Join(result.fuzzyHits, ", ")
+            }
+            if len(result.keywords) > 0 {
+                keywordInfo += " | 评分: " + result.scoreInfo()
             }
             
             log.Printf("%s: [%s] 标题: %s | 匹配关键词: %s", logMessage, url, item.Title, keywordInfo)
+
+            // 跨订阅去重：推送目标相同的订阅中，同一篇文章只推送第一次出现的
+            if original := m.checkDuplicate(item, sent, url, feed.Route); original != nil {
+                log.Printf("🔁 跳过重复文章: [%s] %s | 已通过 %s 推送过", url, item.Title, original.source)
+                if err := m.db.MarkAsSuppressed(sent, original.key); err != nil {
+                    log.Printf("❌ 记录重复文章失败: %v", err)
+                }
+                continue
+            }
             
-            if err := m.messageHandler(item.Title, item.Link, feed.Group, *item.PublishedParsed, matchedKeywords); err != nil {
+            entry.MatchedKeywords = result.keywords
+            entry.FuzzyKeywords = result.fuzzy
+            entry.Score = result.score
+            if err := m.messageHandler(entry); err != nil {
                 log.Printf("❌ 发送消息失败: %v", err)
+                sendFailed = true
             } else {
                 log.Printf("✅ 消息发送成功: %s", item.Title)
-                m.db.MarkAsSent(item.Link)
+                m.db.MarkAsSent(sent)
             }
+        } else if len(result.excluded) > 0 {
+            // 命中排除词或全局屏蔽词
+            excludedArticles++
+            log.Printf("🚫 新文章命中%s: [%s] %s | %s", result.excludedBy, url, item.Title, strings.Join(result.excluded, ", "))
+        } else if len(result.keywords) > 0 || len(result.penalties) > 0 {
+            // 命中了关键词但总分不足
+            log.Printf("📉 新文章评分不足: [%s] %s | 命中关键词: %s | 评分: %s | 需要: %s",
+                url, item.Title, strings.Join(result.keywords, ", "), result.scoreInfo(), minScoreText(feed.MinScore))
         } else {
-            // 如果是新文章但未匹配关键词
-            if !m.db.WasSent(item.Link) {
-                log.Printf("📄 新文章未匹配关键词: [%s] %s", url, item.Title)
-            }
+            // 新文章但未匹配关键词
+            log.Printf("📄 新文章未匹配关键词: [%s] %s", url, item.Title)
         }
     }
     
     // 输出Feed检查摘要
-    log.Printf("📝 Feed检查完成: %s | 总文章: %d, 新文章: %d, 匹配文章: %d", 
-        url, totalArticles, newArticles, matchedArticles)
+    log.Printf("📝 Feed检查完成: %s | 总文章: %d, 新文章: %d, 匹配文章: %d, 过期文章: %d, 排除文章: %d", 
+        url, totalArticles, newArticles, matchedArticles, staleArticles, excludedArticles)
 }
 
-// normalizeText 标准化文本，处理特殊字符和空白
-func normalizeText(text string) string {
-    // 1. 转换为小写
-    text = strings.ToLower(text)
-    
-    // 2. 替换常见的特殊字符组合
-    replacements := map[string]string{
-        "c++": "cpp",
-        "c#": "csharp",
-        ".net": "dotnet",
+// primeItems 处理订阅URL的首次抓取：除最新的 PrimeSendNewest 篇外，
+// 其余文章只标记为已发送而不推送，返回被标记的文章集合
+func (m *Manager) primeItems(feed *Feed, url string, items []*gofeed.Item) map[*gofeed.Item]bool {
+    sorted := make([]*gofeed.Item, len(items))
+    copy(sorted, items)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        return itemSortTime(sorted[i]).After(itemSortTime(sorted[j]))
+    })
+
+    primed := make(map[*gofeed.Item]bool)
+    for i, item := range sorted {
+        if i < feed.PrimeSendNewest {
+            continue
+        }
+        primed[item] = true
+        key := itemKey(item, feed)
+        if m.wasSent(feed, item, key) {
+            continue
+        }
+        if err := m.db.MarkAsSent(sentKey(feed, key)); err != nil {
+            log.Printf("❌ 标记文章失败: %v", err)
+        }
     }
-    
-    for old, new := range replacements {
-        text = strings.ReplaceAll(text, old, new)
+
+    log.Printf("🌱 订阅 [%s] 首次抓取Feed: %s | 已标记 %d 篇文章为已读，最新 %d 篇按关键词过滤后推送", feed.ID, url, len(primed), len(items)-len(primed))
+    return primed
+}
+
+// resolveItemDate 确定文章的时间：依次使用发布时间、更新时间，
+// 两者都没有时使用存储中记录的首次发现时间
+func (m *Manager) resolveItemDate(item *gofeed.Item, key string) time.Time {
+    if t := itemSortTime(item); !t.IsZero() {
+        return t
     }
-    
-    // 3. 清理特殊字符，保留字母、数字和空格
-    var result strings.Builder
-    for _, ch := range text {
-        if unicode.IsLetter(ch) || unicode.IsNumber(ch) || unicode.IsSpace(ch) {
-            result.WriteRune(ch)
-        } else {
-            // 用空格替换特殊字符
-            result.WriteRune(' ')
-        }
+    return m.db.FirstSeen(key)
+}
+
+// itemSortTime 返回用于排序的文章时间，优先使用发布时间
+func itemSortTime(item *gofeed.Item) time.Time {
+    if item.PublishedParsed != nil {
+        return *item.PublishedParsed
     }
-    
-    // 4. 规范化空白字符
-    return strings.Join(strings.Fields(result.String()), " ")
+    if item.UpdatedParsed != nil {
+        return *item.UpdatedParsed
+    }
+    return time.Time{}
+}
+
+// defaultNormalizer 默认的标准化规则，不受配置影响。保留最初内置的符号替换，
+// 使按标题生成的去重标识不随同义词配置变化
+var defaultNormalizer = newNormalizer(NormalizeOptions{Synonyms: map[string]string{
+    "c++":  "cpp",
+    "c#":   "csharp",
+    ".net": "dotnet",
+}}, false)
+
+// normalizeText 按默认规则标准化文本，用于去重标识等需要保持稳定的场景
+func normalizeText(text string) string {
+    return defaultNormalizer.normalize(text)
 }
 
-// isWordMatch 检查单词是否完全匹配
-func isWordMatch(text, keyword string) bool {
-    words := strings.Fields(text)
-    for _, word := range words {
-        if word == keyword {
+// isWordMatch 检查关键词的分词结果是否作为完整的词连续出现在文本的分词结果中，
+// 中文按词典分词，其他文字按空格分词
+func isWordMatch(words, keywordWords []string) bool {
+    if len(keywordWords) == 0 {
+        return false
+    }
+    for i := 0; i+len(keywordWords) <= len(words); i++ {
+        matched := true
+        for j, keywordWord := range keywordWords {
+            if words[i+j] != keywordWord {
+                matched = false
+                break
+            }
+        }
+        if matched {
             return true
         }
     }
@@ -257,55 +614,137 @@ func contains(slice []string, str string) bool {
     return false
 }
 
-func (m *Manager) matchKeywords(item *gofeed.Item, feed *Feed) []string {
-    if m.db.WasSent(item.Link) {
-        return nil
-    }
+// matchResult 关键词匹配结果
+type matchResult struct {
+    matched    bool     // 文章是否需要推送
+    keywords   []string // 命中的关键词，未配置关键词时为空
+    excluded   []string // 命中的排除词或屏蔽词
+    excludedBy string   // 排除来源：排除词或全局屏蔽词
+    fuzzy      []string // 命中的关键词中通过模糊匹配命中的部分
+    fuzzyHits  []string // 模糊匹配的详情，如 "hetzner≈hetzer"，用于日志
+    penalties  []string // 命中的负权重关键词
+    score      float64  // 命中规则的权重之和
+}
 
-    if len(feed.Keywords) == 0 {
-        // 如果没有配置关键词，返回一个特殊标记
-        return []string{"__NO_KEYWORDS__"}
+// scoreInfo 返回总分及命中的负权重关键词，用于日志
+func (r matchResult) scoreInfo() string {
+    info := filter.FormatWeight(r.score)
+    if len(r.penalties) > 0 {
+        info += "（减分: " + strings.Join(r.penalties, ", ") + "）"
     }
+    return info
+}
+
+// minScoreText 返回推送所需分数的说明
+func minScoreText(minScore float64) string {
+    return "大于" + filter.FormatWeight(minScore)
+}
+
+// matchKeywords 先检查全局屏蔽词和订阅的排除词，命中任意一条即不推送；
+// 再检查关键词规则并累加命中规则的权重，总分超过订阅的分数阈值才推送，
+// 未设置分数阈值时总分大于0即推送；未配置关键词时推送所有文章
+func (m *Manager) matchKeywords(item *model.Item, feed *Feed) matchResult {
+    segmenter := m.getSegmenter()
+    normalizer := m.getNormalizers().forFeed(feed)
+    fields := newItemFields(item, segmenter, normalizer)
+
+    // fuzzyHits 记录通过模糊匹配命中的关键词及文章中相近的词
+    fuzzyHits := make(map[string]string)
+
+    // matchTerm 检查单个关键词是否出现在订阅的匹配字段中，带字段前缀时只检查该字段；
+    // fuzzy 为 true 时，精确匹配失败后再尝试模糊匹配
+    matchTerm := func(keyword string, fuzzy bool) bool {
+        field, text := filter.SplitField(keyword)
+        matchFields := feed.MatchFields
+        if field != "" {
+            matchFields = []string{field}
+        }
 
-    // 标准化文本
-    normalizedTitle := normalizeText(item.Title)
-    normalizedDesc := normalizeText(item.Description)
-    
-    var matched []string
-    
-    // 检查每个关键词
-    for _, keyword := range feed.Keywords {
         // 标准化关键词
-        normalizedKeyword := normalizeText(keyword)
-        
-        // 首先尝试完整词匹配
-        if isWordMatch(normalizedTitle, normalizedKeyword) {
-            if !contains(matched, keyword) {
-                matched = append(matched, keyword)
-            }
-            continue
+        normalizedKeyword := normalizer.normalize(text)
+        if normalizedKeyword == "" {
+            return false
         }
-        
-        if isWordMatch(normalizedDesc, normalizedKeyword) {
-            if !contains(matched, keyword) {
-                matched = append(matched, keyword)
+
+        for _, field := range matchFields {
+            // 分类按整体相等匹配
+            if field == filter.FieldCategory {
+                for _, category := range item.Categories {
+                    if normalizer.normalize(category) == normalizedKeyword {
+                        return true
+                    }
+                }
+                continue
+            }
+
+            // 首先尝试完整词匹配，如果允许部分匹配再尝试部分匹配
+            if isWordMatch(fields.words(field), segmenter.Cut(normalizedKeyword)) {
+                return true
+            }
+            if feed.AllowPartMatch && strings.Contains(fields.normalized(field), normalizedKeyword) {
+                return true
             }
-            continue
         }
-        
-        // 如果允许部分匹配且没有找到完整匹配，尝试部分匹配
-        if feed.AllowPartMatch {
-            if strings.Contains(normalizedTitle, normalizedKeyword) {
-                if !contains(matched, keyword) {
-                    matched = append(matched, keyword)
+
+        // 所有字段都未精确命中时再尝试模糊匹配，分类不参与模糊匹配
+        if fuzzy {
+            for _, field := range matchFields {
+                if field == filter.FieldCategory {
+                    continue
                 }
-            } else if strings.Contains(normalizedDesc, normalizedKeyword) {
-                if !contains(matched, keyword) {
-                    matched = append(matched, keyword)
+                if hit, ok := fuzzyWordMatch(fields.words(field), segmenter.Cut(normalizedKeyword)); ok {
+                    fuzzyHits[displayKeyword(keyword)] = hit
+                    return true
+                }
+            }
+        }
+        return false
+    }
+
+    // matchRegex 正则规则在订阅匹配字段的原文上匹配
+    matchRegex := func(re *regexp.Regexp) bool {
+        for _, field := range feed.MatchFields {
+            for _, value := range fields.raw(field) {
+                if re.MatchString(value) {
+                    return true
                 }
             }
         }
+        return false
     }
 
-    return matched
+    // 屏蔽词和排除词只做精确匹配，避免误伤
+    rm := matcher{
+        term:  func(keyword string) bool { return matchTerm(keyword, false) },
+        regex: matchRegex,
+    }
+    if blocked := matchRules(m.getBlocklist(), rm); len(blocked) > 0 {
+        return matchResult{excluded: blocked, excludedBy: "全局屏蔽词"}
+    }
+    if excluded := matchRules(feed.excludeRules, rm); len(excluded) > 0 {
+        return matchResult{excluded: excluded, excludedBy: "排除词"}
+    }
+
+    if len(feed.Keywords) == 0 && len(feed.Regex) == 0 && len(feed.KeywordSets) == 0 {
+        // 如果没有配置关键词、正则规则和关键词组，推送所有文章
+        return matchResult{matched: true}
+    }
+
+    // 关键词组在匹配时按名称查找，修改关键词组后立即对所有引用它的订阅生效
+    rules := feed.rules
+    if setRules := m.getKeywordSetRules(feed); len(setRules) > 0 {
+        rules = append(append([]keywordRule(nil), feed.rules...), setRules...)
+    }
+
+    rm.term = func(keyword string) bool { return matchTerm(keyword, feed.FuzzyMatch) }
+    matched, penalties, score := scoreRules(rules, rm)
+    result := matchResult{keywords: matched, penalties: penalties, score: score}
+    result.matched = score > feed.MinScore
+    for _, keyword := range matched {
+        if hit, ok := fuzzyHits[keyword]; ok {
+            result.fuzzy = append(result.fuzzy, keyword)
+            result.fuzzyHits = append(result.fuzzyHits, keyword+"≈"+hit)
+        }
+    }
+    return result
 }
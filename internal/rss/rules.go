@@ -0,0 +1,269 @@
+package rss
+
+import (
+    "log"
+    "regexp"
+    "strings"
+
+    "rss2tg/internal/filter"
+    "rss2tg/internal/model"
+    "rss2tg/internal/segment"
+)
+
+// RegexRule 命名的正则匹配规则
+type RegexRule struct {
+    Name    string
+    Pattern string
+    Weight  float64 // 命中时计入的分数，0 表示使用默认权重 1
+}
+
+// keywordRule 一条编译后的关键词规则：普通关键词、布尔表达式或正则
+type keywordRule struct {
+    keyword string         // 命中时展示的名称
+    expr    *filter.Expr   // 布尔表达式
+    regex   *regexp.Regexp // 正则规则
+    weight  float64        // 命中时计入的分数，可以为负数
+}
+
+// matcher 对单篇文章执行关键词和正则匹配
+type matcher struct {
+    term  filter.MatchFunc           // 检查普通关键词
+    regex func(*regexp.Regexp) bool // 检查正则规则
+}
+
+// compileKeywords 编译关键词规则，无效的表达式记录日志后忽略，owner 用于日志中说明规则来源
+func compileKeywords(owner string, keywords []string) []keywordRule {
+    rules := make([]keywordRule, 0, len(keywords))
+    for _, keyword := range keywords {
+        keyword, weight, _ := filter.SplitWeight(keyword)
+        rule := keywordRule{keyword: keyword, weight: weight}
+        switch {
+        case filter.IsRegex(keyword):
+            re, err := filter.CompileRegex(keyword)
+            if err != nil {
+                log.Printf("❌ %s 中的正则规则无效，已忽略: %v", owner, err)
+                continue
+            }
+            rule.regex = re
+        case filter.IsExpression(keyword):
+            expr, err := filter.Parse(keyword)
+            if err != nil {
+                log.Printf("❌ %s 中的关键词表达式无效，已忽略: %v", owner, err)
+                continue
+            }
+            rule.expr = expr
+        }
+        rules = append(rules, rule)
+    }
+    return rules
+}
+
+// compileRegexRules 编译命名的正则规则，命中时以规则名称展示
+func compileRegexRules(owner string, regexRules []RegexRule) []keywordRule {
+    rules := make([]keywordRule, 0, len(regexRules))
+    for _, regexRule := range regexRules {
+        re, err := filter.CompilePattern(regexRule.Pattern)
+        if err != nil {
+            log.Printf("❌ %s 中的正则规则 %q 无效，已忽略: %v", owner, regexRule.Name, err)
+            continue
+        }
+        name := regexRule.Name
+        if name == "" {
+            name = regexRule.Pattern
+        }
+        weight := regexRule.Weight
+        if weight == 0 {
+            weight = filter.DefaultWeight
+        }
+        rules = append(rules, keywordRule{keyword: name, regex: re, weight: weight})
+    }
+    return rules
+}
+
+// match 计算规则，返回命中时用于展示的关键词
+func (r keywordRule) match(m matcher) []string {
+    switch {
+    case r.regex != nil:
+        if m.regex(r.regex) {
+            return []string{r.keyword}
+        }
+        return nil
+    case r.expr != nil:
+        ok, terms := r.expr.Match(m.term)
+        if !ok {
+            return nil
+        }
+        // 仅由 NOT 构成的表达式命中时没有具体关键词，展示表达式本身
+        if len(terms) == 0 {
+            return []string{r.expr.String()}
+        }
+        for i, term := range terms {
+            terms[i] = displayKeyword(term)
+        }
+        return terms
+    default:
+        if m.term(r.keyword) {
+            return []string{displayKeyword(r.keyword)}
+        }
+        return nil
+    }
+}
+
+// displayKeyword 返回用于展示的关键词，去掉字段前缀
+func displayKeyword(term string) string {
+    _, text := filter.SplitField(term)
+    return text
+}
+
+// matchRules 检查每条规则，多条规则之间为“或”的关系，返回去重后的命中关键词
+func matchRules(rules []keywordRule, m matcher) []string {
+    var matched []string
+    for _, rule := range rules {
+        for _, keyword := range rule.match(m) {
+            if !contains(matched, keyword) {
+                matched = append(matched, keyword)
+            }
+        }
+    }
+    return matched
+}
+
+// scoreRules 检查每条规则并累加命中规则的权重，返回正权重规则命中的关键词、
+// 负权重规则命中的关键词和总分
+func scoreRules(rules []keywordRule, m matcher) (keywords, penalties []string, score float64) {
+    for _, rule := range rules {
+        matched := rule.match(m)
+        if len(matched) == 0 {
+            continue
+        }
+        score += rule.weight
+        for _, keyword := range matched {
+            if rule.weight < 0 {
+                if !contains(penalties, keyword) {
+                    penalties = append(penalties, keyword)
+                }
+            } else if !contains(keywords, keyword) {
+                keywords = append(keywords, keyword)
+            }
+        }
+    }
+    return keywords, penalties, score
+}
+
+// matchFields 将配置的匹配字段转换为标准字段名，忽略不支持的字段，为空时使用默认字段
+func matchFields(names []string) []string {
+    fields := make([]string, 0, len(names))
+    for _, name := range names {
+        if field, ok := filter.NormalizeField(name); ok && !contains(fields, field) {
+            fields = append(fields, field)
+        }
+    }
+    if len(fields) == 0 {
+        return filter.DefaultFields
+    }
+    return fields
+}
+
+// itemFields 缓存一篇文章各字段的原文、标准化文本和分词结果，避免每条规则重复计算
+type itemFields struct {
+    item           *model.Item
+    segmenter      *segment.Segmenter
+    normalizer     *normalizer
+    normalizedText map[string]string
+    segmented      map[string][]string
+}
+
+func newItemFields(item *model.Item, segmenter *segment.Segmenter, normalizer *normalizer) *itemFields {
+    return &itemFields{
+        item:           item,
+        segmenter:      segmenter,
+        normalizer:     normalizer,
+        normalizedText: make(map[string]string),
+        segmented:      make(map[string][]string),
+    }
+}
+
+// raw 返回字段原文，描述和正文去除 HTML 标签，分类返回每个分类
+func (f *itemFields) raw(field string) []string {
+    switch field {
+    case filter.FieldTitle:
+        return []string{f.item.Title}
+    case filter.FieldDescription:
+        return []string{model.StripHTML(f.item.Description)}
+    case filter.FieldContent:
+        return []string{model.StripHTML(f.item.Content)}
+    case filter.FieldAuthor:
+        return []string{f.item.Author}
+    case filter.FieldCategory:
+        return f.item.Categories
+    }
+    return nil
+}
+
+// normalized 返回字段标准化后的文本
+func (f *itemFields) normalized(field string) string {
+    if text, ok := f.normalizedText[field]; ok {
+        return text
+    }
+    text := f.normalizer.normalize(strings.Join(f.raw(field), " "))
+    f.normalizedText[field] = text
+    return text
+}
+
+// words 返回字段标准化文本的分词结果
+func (f *itemFields) words(field string) []string {
+    if words, ok := f.segmented[field]; ok {
+        return words
+    }
+    words := f.segmenter.Cut(f.normalized(field))
+    f.segmented[field] = words
+    return words
+}
+
+// newSegmenter 创建分词器，把所有订阅的关键词、排除词、全局关键词（屏蔽词和关键词组）
+// 和同义词的标准词加入用户词典，保证中文关键词本身能被切分为一个完整的词
+func newSegmenter(feeds []*Feed, global []string, normalizer *normalizer) *segment.Segmenter {
+    var words []string
+    words = append(words, keywordTerms(global, normalizer)...)
+    for _, feed := range feeds {
+        words = append(words, keywordTerms(feed.Keywords, normalizer)...)
+        words = append(words, keywordTerms(feed.ExcludeKeywords, normalizer)...)
+    }
+    for _, s := range normalizer.synonyms {
+        words = append(words, strings.Fields(s.canonical)...)
+    }
+    return segment.New(words)
+}
+
+// globalKeywords 返回不属于单个订阅的关键词：全局屏蔽词和所有关键词组中的关键词
+func globalKeywords(options Options) []string {
+    keywords := append([]string(nil), options.Blocklist...)
+    for _, set := range options.KeywordSets {
+        keywords = append(keywords, set...)
+    }
+    return keywords
+}
+
+// keywordTerms 返回关键词规则中出现的所有词（标准化后，不含字段前缀），忽略正则规则
+func keywordTerms(keywords []string, normalizer *normalizer) []string {
+    var terms []string
+    for _, keyword := range keywords {
+        keyword, _, _ = filter.SplitWeight(keyword)
+        if filter.IsRegex(keyword) {
+            continue
+        }
+        candidates := []string{keyword}
+        if filter.IsExpression(keyword) {
+            expr, err := filter.Parse(keyword)
+            if err != nil {
+                continue
+            }
+            candidates = expr.Terms()
+        }
+        for _, term := range candidates {
+            _, text := filter.SplitField(term)
+            terms = append(terms, strings.Fields(normalizer.normalize(text))...)
+        }
+    }
+    return terms
+}
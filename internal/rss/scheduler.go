@@ -0,0 +1,214 @@
+package rss
+
+import (
+    "container/heap"
+    "math/rand"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    defaultMaxConcurrency     = 8                      // 默认全局最大并发抓取数
+    defaultPerHostConcurrency = 2                      // 默认同一主机最大并发抓取数
+    defaultStartJitter        = 30 * time.Second       // 默认首次抓取的随机延迟上限
+    hostRetryDelay            = 500 * time.Millisecond // 主机并发已满时的重试间隔
+    minInterval               = 30 * time.Second       // 订阅检查间隔的下限，防止间隔过小时反复请求
+)
+
+// job 表示一个需要定时抓取的URL
+type job struct {
+    feed    *Feed
+    url     string
+    host    string
+    due     time.Time // 下一次到期时间
+    index   int       // 在优先队列中的位置，-1 表示不在队列中
+    removed bool      // 订阅已移除，执行结束后不再入队
+}
+
+// jobQueue 按到期时间排序的优先队列
+type jobQueue []*job
+
+func (q jobQueue) Len() int           { return len(q) }
+func (q jobQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q jobQueue) Swap(i, j int) {
+    q[i], q[j] = q[j], q[i]
+    q[i].index = i
+    q[j].index = j
+}
+
+func (q *jobQueue) Push(x interface{}) {
+    j := x.(*job)
+    j.index = len(*q)
+    *q = append(*q, j)
+}
+
+func (q *jobQueue) Pop() interface{} {
+    old := *q
+    n := len(old)
+    j := old[n-1]
+    old[n-1] = nil
+    j.index = -1
+    *q = old[:n-1]
+    return j
+}
+
+// scheduler 统一调度所有订阅URL的抓取，限制全局和单个主机的并发数
+type scheduler struct {
+    queue   jobQueue
+    jobs    map[*Feed][]*job
+    running int
+    hosts   map[string]int
+    wake    chan struct{}
+    options func() Options
+    run     func(feed *Feed, url string) time.Time // 执行抓取，返回下一次到期时间
+    rand    *rand.Rand
+    mu      sync.Mutex
+}
+
+func newScheduler(options func() Options, run func(feed *Feed, url string) time.Time) *scheduler {
+    return &scheduler{
+        jobs:    make(map[*Feed][]*job),
+        hosts:   make(map[string]int),
+        wake:    make(chan struct{}, 1),
+        options: options,
+        run:     run,
+        rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+    }
+}
+
+// add 将订阅的所有URL加入调度，首次到期时间带随机延迟以错开请求
+func (s *scheduler) add(feed *Feed) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    jitter := s.options().StartJitter
+    if jitter <= 0 {
+        jitter = defaultStartJitter
+    }
+    if feed.Interval > 0 && jitter > feed.Interval {
+        jitter = feed.Interval
+    }
+
+    now := time.Now()
+    for _, feedURL := range feed.URLs {
+        j := &job{
+            feed: feed,
+            url:  feedURL,
+            host: hostOf(feedURL),
+            due:  now.Add(time.Duration(s.rand.Int63n(int64(jitter) + 1))),
+        }
+        heap.Push(&s.queue, j)
+        s.jobs[feed] = append(s.jobs[feed], j)
+    }
+    s.signal()
+}
+
+// remove 将订阅从调度中移除，正在执行的抓取完成后不再入队
+func (s *scheduler) remove(feed *Feed) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for _, j := range s.jobs[feed] {
+        j.removed = true
+        if j.index >= 0 {
+            heap.Remove(&s.queue, j.index)
+        }
+    }
+    delete(s.jobs, feed)
+}
+
+// loop 持续取出到期任务并发执行，不会返回
+func (s *scheduler) loop() {
+    for {
+        j := s.next()
+        go s.execute(j)
+    }
+}
+
+// next 阻塞直到有到期且全局和主机并发均未满的任务
+func (s *scheduler) next() *job {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for {
+        options := s.options()
+        maxConcurrency := options.MaxConcurrency
+        if maxConcurrency <= 0 {
+            maxConcurrency = defaultMaxConcurrency
+        }
+        perHost := options.PerHostConcurrency
+        if perHost <= 0 {
+            perHost = defaultPerHostConcurrency
+        }
+
+        var timer *time.Timer
+        var timeout <-chan time.Time
+        if s.running < maxConcurrency && len(s.queue) > 0 {
+            now := time.Now()
+            top := s.queue[0]
+            if !top.due.After(now) {
+                if s.hosts[top.host] < perHost {
+                    heap.Pop(&s.queue)
+                    s.running++
+                    s.hosts[top.host]++
+                    return top
+                }
+                // 该主机的并发已满，稍后再试
+                top.due = now.Add(hostRetryDelay)
+                heap.Fix(&s.queue, 0)
+                continue
+            }
+            timer = time.NewTimer(top.due.Sub(now))
+            timeout = timer.C
+        }
+
+        s.mu.Unlock()
+        select {
+        case <-s.wake:
+        case <-timeout:
+        }
+        if timer != nil {
+            timer.Stop()
+        }
+        s.mu.Lock()
+    }
+}
+
+// execute 执行一次抓取，结束后按返回的到期时间重新入队
+func (s *scheduler) execute(j *job) {
+    next := s.run(j.feed, j.url)
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.running--
+    s.hosts[j.host]--
+    if s.hosts[j.host] <= 0 {
+        delete(s.hosts, j.host)
+    }
+    if !j.removed {
+        j.due = next
+        heap.Push(&s.queue, j)
+    }
+    s.signal()
+}
+
+// signal 唤醒调度循环
+func (s *scheduler) signal() {
+    select {
+    case s.wake <- struct{}{}:
+    default:
+    }
+}
+
+// hostOf 返回URL的主机名，用于按主机限制并发
+func hostOf(feedURL string) string {
+    u, err := url.Parse(feedURL)
+    if err != nil || u.Host == "" {
+        return feedURL
+    }
+    return strings.ToLower(u.Host)
+}
@@ -0,0 +1,179 @@
+// Package segment 提供基于词典的中文分词，用于关键词的完整词匹配。
+//
+// 内置词典随程序打包（dict.txt），不依赖网络。连续的汉字按双向最大匹配切分，
+// 字母、数字等非汉字按原有的空格分隔，并在汉字与非汉字的交界处断开。
+package segment
+
+import (
+    _ "embed"
+    "strings"
+    "sync"
+    "unicode"
+    "unicode/utf8"
+)
+
+//go:embed dict.txt
+var builtinDict string
+
+// Segmenter 中文分词器，可在内置词典的基础上追加用户词
+type Segmenter struct {
+    base   *dictionary
+    user   map[string]bool
+    maxLen int // 词典中最长词的字符数
+}
+
+type dictionary struct {
+    words  map[string]bool
+    maxLen int
+}
+
+var (
+    defaultDict     *dictionary
+    defaultDictOnce sync.Once
+)
+
+// loadDefaultDict 解析内置词典，只执行一次
+func loadDefaultDict() *dictionary {
+    defaultDictOnce.Do(func() {
+        dict := &dictionary{words: make(map[string]bool)}
+        for _, line := range strings.Split(builtinDict, "\n") {
+            word := strings.TrimSpace(line)
+            if word == "" || strings.HasPrefix(word, "#") {
+                continue
+            }
+            dict.words[word] = true
+            if n := utf8.RuneCountInString(word); n > dict.maxLen {
+                dict.maxLen = n
+            }
+        }
+        defaultDict = dict
+    })
+    return defaultDict
+}
+
+// New 创建使用内置词典的分词器，userWords 为追加的用户词（如订阅的关键词）
+func New(userWords []string) *Segmenter {
+    s := &Segmenter{base: loadDefaultDict(), user: make(map[string]bool)}
+    s.maxLen = s.base.maxLen
+    for _, word := range userWords {
+        word = strings.TrimSpace(word)
+        if !ContainsHan(word) {
+            continue
+        }
+        s.user[word] = true
+        if n := utf8.RuneCountInString(word); n > s.maxLen {
+            s.maxLen = n
+        }
+    }
+    return s
+}
+
+// Cut 将文本切分为词，汉字按词典切分，其他字符按空白切分
+func (s *Segmenter) Cut(text string) []string {
+    var words []string
+    for _, field := range strings.Fields(text) {
+        runes := []rune(field)
+        start := 0
+        for start < len(runes) {
+            han := isHan(runes[start])
+            end := start + 1
+            for end < len(runes) && isHan(runes[end]) == han {
+                end++
+            }
+            if han {
+                words = append(words, s.cutHan(runes[start:end])...)
+            } else {
+                words = append(words, string(runes[start:end]))
+            }
+            start = end
+        }
+    }
+    return words
+}
+
+// cutHan 对连续汉字做双向最大匹配：优先选词数少的结果，
+// 词数相同时选单字少的结果，仍相同时采用逆向结果
+func (s *Segmenter) cutHan(runes []rune) []string {
+    forward := s.forwardMax(runes)
+    backward := s.backwardMax(runes)
+    if len(forward) != len(backward) {
+        if len(forward) < len(backward) {
+            return forward
+        }
+        return backward
+    }
+    if singleCount(forward) < singleCount(backward) {
+        return forward
+    }
+    return backward
+}
+
+// forwardMax 正向最大匹配
+func (s *Segmenter) forwardMax(runes []rune) []string {
+    var words []string
+    for i := 0; i < len(runes); {
+        n := s.maxLen
+        if n > len(runes)-i {
+            n = len(runes) - i
+        }
+        for ; n > 1; n-- {
+            if s.contains(string(runes[i : i+n])) {
+                break
+            }
+        }
+        words = append(words, string(runes[i:i+n]))
+        i += n
+    }
+    return words
+}
+
+// backwardMax 逆向最大匹配
+func (s *Segmenter) backwardMax(runes []rune) []string {
+    var words []string
+    for j := len(runes); j > 0; {
+        n := s.maxLen
+        if n > j {
+            n = j
+        }
+        for ; n > 1; n-- {
+            if s.contains(string(runes[j-n : j])) {
+                break
+            }
+        }
+        words = append(words, string(runes[j-n:j]))
+        j -= n
+    }
+    // 逆向结果需要反转为原文顺序
+    for i, k := 0, len(words)-1; i < k; i, k = i+1, k-1 {
+        words[i], words[k] = words[k], words[i]
+    }
+    return words
+}
+
+func (s *Segmenter) contains(word string) bool {
+    return s.user[word] || s.base.words[word]
+}
+
+func singleCount(words []string) int {
+    count := 0
+    for _, word := range words {
+        if utf8.RuneCountInString(word) == 1 {
+            count++
+        }
+    }
+    return count
+}
+
+// ContainsHan 判断文本是否包含汉字
+func ContainsHan(text string) bool {
+    for _, ch := range text {
+        if isHan(ch) {
+            return true
+        }
+    }
+    return false
+}
+
+func isHan(ch rune) bool {
+    return unicode.Is(unicode.Han, ch)
+}
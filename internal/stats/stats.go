@@ -10,12 +10,13 @@ import (
 )
 
 type Stats struct {
-    DailyCount  int       `json:"daily_count"`
-    WeeklyCount int       `json:"weekly_count"`
-    TotalCount  int       `json:"total_count"`
-    LastReset   time.Time `json:"last_reset"`
-    filePath    string
-    mu          sync.Mutex
+    DailyCount    int            `json:"daily_count"`
+    WeeklyCount   int            `json:"weekly_count"`
+    TotalCount    int            `json:"total_count"`
+    LastReset     time.Time      `json:"last_reset"`
+    Subscriptions map[string]int `json:"subscriptions,omitempty"` // 按订阅ID统计的推送总数
+    filePath      string
+    mu            sync.Mutex
 }
 
 func NewStats(filePath string) (*Stats, error) {
@@ -48,12 +49,18 @@ func (s *Stats) save() error {
     return ioutil.WriteFile(s.filePath, data, 0644)
 }
 
-func (s *Stats) IncrementMessageCount() {
+func (s *Stats) IncrementMessageCount(subscriptionID string) {
     s.mu.Lock()
     defer s.mu.Unlock()
     s.DailyCount++
     s.WeeklyCount++
     s.TotalCount++
+    if subscriptionID != "" {
+        if s.Subscriptions == nil {
+            s.Subscriptions = make(map[string]int)
+        }
+        s.Subscriptions[subscriptionID]++
+    }
     if err := s.save(); err != nil {
         log.Printf("保存统计信息失败: %v", err)
     }
@@ -65,6 +72,13 @@ func (s *Stats) GetMessageCounts() (int, int, int) {
     return s.DailyCount, s.WeeklyCount, s.TotalCount
 }
 
+// GetSubscriptionCount 返回指定订阅的推送总数
+func (s *Stats) GetSubscriptionCount(subscriptionID string) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.Subscriptions[subscriptionID]
+}
+
 func (s *Stats) resetCounters() {
     for {
         now := time.Now()
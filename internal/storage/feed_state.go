@@ -0,0 +1,122 @@
+package storage
+
+import (
+    "encoding/json"
+    "io/ioutil"
+    "log"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// FeedState 记录订阅在单个Feed URL上的抓取状态，随 sent_items.txt 一起持久化。
+// 状态按订阅和URL分别保存，同一URL的多个订阅各自发起条件请求、各自判断首次抓取
+type FeedState struct {
+    ETag         string    `json:"etag,omitempty"`          // 上次响应的 ETag
+    LastModified string    `json:"last_modified,omitempty"` // 上次响应的 Last-Modified
+    FirstFetched time.Time `json:"first_fetched,omitempty"` // 首次成功抓取的时间
+}
+
+// feedStatePath 返回抓取状态文件路径，与已发送项目文件位于同一目录
+func feedStatePath(sentItemsPath string) string {
+    return filepath.Join(filepath.Dir(sentItemsPath), "feed_state.json")
+}
+
+func (s *Storage) loadFeedStates() {
+    data, err := ioutil.ReadFile(s.statePath)
+    if err != nil {
+        if !os.IsNotExist(err) {
+            log.Printf("读取Feed状态文件时出错: %v", err)
+        }
+        return
+    }
+
+    if err := json.Unmarshal(data, &s.feedStates); err != nil {
+        log.Printf("解析Feed状态文件时出错: %v", err)
+        s.feedStates = make(map[string]*FeedState)
+        return
+    }
+
+    // 旧版本按URL保存的状态不再使用，下次保存时清理
+    for key := range s.feedStates {
+        if !strings.Contains(key, stateKeySeparator) {
+            delete(s.feedStates, key)
+        }
+    }
+}
+
+// saveFeedStates 将抓取状态写入文件，调用方需持有锁
+func (s *Storage) saveFeedStates() error {
+    data, err := json.MarshalIndent(s.feedStates, "", "  ")
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(s.statePath, data, 0644)
+}
+
+// feedState 获取指定键的状态，不存在时创建，调用方需持有锁
+func (s *Storage) feedState(key string) *FeedState {
+    state, ok := s.feedStates[key]
+    if !ok {
+        state = &FeedState{}
+        s.feedStates[key] = state
+    }
+    return state
+}
+
+// stateKeySeparator 状态键中订阅ID与URL的分隔符
+const stateKeySeparator = "|"
+
+// stateKey 返回订阅在指定URL上的状态键
+func stateKey(subscriptionID, url string) string {
+    return subscriptionID + stateKeySeparator + url
+}
+
+// GetValidators 返回订阅上次为指定URL保存的 ETag 和 Last-Modified
+func (s *Storage) GetValidators(subscriptionID, url string) (etag, lastModified string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if state, ok := s.feedStates[stateKey(subscriptionID, url)]; ok {
+        return state.ETag, state.LastModified
+    }
+    return "", ""
+}
+
+// SaveValidators 保存订阅在指定URL上的 ETag 和 Last-Modified，用于该订阅下次条件请求。
+// 校验信息不能在订阅间共用，否则先抓取的订阅保存后，其他订阅会一直得到 304 而跳过匹配
+func (s *Storage) SaveValidators(subscriptionID, url, etag, lastModified string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    state := s.feedState(stateKey(subscriptionID, url))
+    if state.ETag == etag && state.LastModified == lastModified {
+        return nil
+    }
+    state.ETag = etag
+    state.LastModified = lastModified
+    return s.saveFeedStates()
+}
+
+// HasFetched 检查订阅是否曾经成功抓取过指定URL
+func (s *Storage) HasFetched(subscriptionID, url string) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    state, ok := s.feedStates[stateKey(subscriptionID, url)]
+    return ok && !state.FirstFetched.IsZero()
+}
+
+// MarkFetched 记录订阅已成功抓取过指定URL，只在首次抓取时写入
+func (s *Storage) MarkFetched(subscriptionID, url string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    state := s.feedState(stateKey(subscriptionID, url))
+    if !state.FirstFetched.IsZero() {
+        return nil
+    }
+    state.FirstFetched = time.Now()
+    return s.saveFeedStates()
+}
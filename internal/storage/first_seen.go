@@ -0,0 +1,62 @@
+package storage
+
+import (
+    "encoding/json"
+    "io/ioutil"
+    "log"
+    "os"
+    "path/filepath"
+    "time"
+)
+
+// firstSeenRetention 首次出现时间记录的保留时长，超过后在保存时清理
+const firstSeenRetention = 90 * 24 * time.Hour
+
+func firstSeenPath(sentItemsPath string) string {
+    return filepath.Join(filepath.Dir(sentItemsPath), "item_first_seen.json")
+}
+
+func (s *Storage) loadFirstSeen() {
+    data, err := ioutil.ReadFile(s.firstSeenPath)
+    if err != nil {
+        if !os.IsNotExist(err) {
+            log.Printf("读取文章首次出现时间文件时出错: %v", err)
+        }
+        return
+    }
+
+    if err := json.Unmarshal(data, &s.firstSeen); err != nil {
+        log.Printf("解析文章首次出现时间文件时出错: %v", err)
+        s.firstSeen = make(map[string]time.Time)
+    }
+}
+
+// FirstSeen 返回文章首次被发现的时间，首次调用时记录并持久化当前时间，
+// 用于没有发布时间和更新时间的文章
+func (s *Storage) FirstSeen(key string) time.Time {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if seen, ok := s.firstSeen[key]; ok {
+        return seen
+    }
+
+    now := time.Now()
+    s.firstSeen[key] = now
+
+    // 清理过期记录后保存
+    for k, seen := range s.firstSeen {
+        if now.Sub(seen) > firstSeenRetention {
+            delete(s.firstSeen, k)
+        }
+    }
+    data, err := json.Marshal(s.firstSeen)
+    if err != nil {
+        log.Printf("序列化文章首次出现时间失败: %v", err)
+        return now
+    }
+    if err := ioutil.WriteFile(s.firstSeenPath, data, 0644); err != nil {
+        log.Printf("保存文章首次出现时间失败: %v", err)
+    }
+    return now
+}
@@ -2,24 +2,39 @@ package storage
 
 import (
     "bufio"
+    "fmt"
     "log"
     "os"
+    "path/filepath"
     "strings"
     "sync"
+    "time"
+
+    "rss2tg/internal/urlutil"
 )
 
 type Storage struct {
-    sentItems map[string]bool
-    filePath  string
-    mu        sync.Mutex
+    sentItems     map[string]bool
+    filePath      string
+    feedStates    map[string]*FeedState
+    statePath     string
+    firstSeen     map[string]time.Time
+    firstSeenPath string
+    mu            sync.Mutex
 }
 
 func NewStorage(filePath string) *Storage {
     s := &Storage{
-        sentItems: make(map[string]bool),
-        filePath:  filePath,
+        sentItems:     make(map[string]bool),
+        filePath:      filePath,
+        feedStates:    make(map[string]*FeedState),
+        statePath:     feedStatePath(filePath),
+        firstSeen:     make(map[string]time.Time),
+        firstSeenPath: firstSeenPath(filePath),
     }
     s.loadSentItems()
+    s.loadFeedStates()
+    s.loadFirstSeen()
     return s
 }
 
@@ -35,7 +50,16 @@ func (s *Storage) loadSentItems() {
 
     scanner := bufio.NewScanner(file)
     for scanner.Scan() {
-        s.sentItems[strings.TrimSpace(scanner.Text())] = true
+        key := strings.TrimSpace(scanner.Text())
+        if key == "" {
+            continue
+        }
+        s.sentItems[key] = true
+        // 旧记录只保存了原始链接，同时登记其规范化形式，
+        // 这样订阅切换到规范化链接去重时不会重复推送历史文章
+        if urlutil.IsURL(key) {
+            s.sentItems[urlutil.Normalize(key, nil)] = true
+        }
     }
 
     if err := scanner.Err(); err != nil {
@@ -54,14 +78,34 @@ func (s *Storage) MarkAsSent(url string) error {
     defer s.mu.Unlock()
 
     s.sentItems[url] = true
+    return appendLine(s.filePath, url)
+}
+
+// MarkAsSuppressed 将重复文章记录为已处理，不再推送，
+// 并在 suppressed_items.txt 中记录它重复于哪篇已推送的文章
+func (s *Storage) MarkAsSuppressed(key, duplicateOf string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.sentItems[key] = true
+    if err := appendLine(s.filePath, key); err != nil {
+        return err
+    }
+
+    suppressedPath := filepath.Join(filepath.Dir(s.filePath), "suppressed_items.txt")
+    line := fmt.Sprintf("%s\t%s\t%s", time.Now().Format(time.RFC3339), key, duplicateOf)
+    return appendLine(suppressedPath, line)
+}
 
-    file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// appendLine 向文件追加一行内容
+func appendLine(path, line string) error {
+    file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
     if err != nil {
         return err
     }
     defer file.Close()
 
-    if _, err := file.WriteString(url + "\n"); err != nil {
+    if _, err := file.WriteString(line + "\n"); err != nil {
         return err
     }
 
@@ -0,0 +1,64 @@
+// Package textfold 提供匹配前的字符折叠：全角转半角、繁体转简体。
+//
+// 繁简对照表随程序打包（t2s.txt），只收录常用字的一对一转换，不处理词汇差异。
+package textfold
+
+import (
+    _ "embed"
+    "strings"
+    "sync"
+    "unicode/utf8"
+)
+
+//go:embed t2s.txt
+var t2sTable string
+
+var (
+    t2s     map[rune]rune
+    t2sOnce sync.Once
+)
+
+// loadT2S 解析繁简对照表，只执行一次
+func loadT2S() map[rune]rune {
+    t2sOnce.Do(func() {
+        t2s = make(map[rune]rune)
+        for _, line := range strings.Split(t2sTable, "\n") {
+            line = strings.TrimSpace(line)
+            if line == "" || strings.HasPrefix(line, "#") {
+                continue
+            }
+            for _, pair := range strings.Fields(line) {
+                traditional, size := utf8.DecodeRuneInString(pair)
+                simplified, _ := utf8.DecodeRuneInString(pair[size:])
+                if traditional != utf8.RuneError && simplified != utf8.RuneError {
+                    t2s[traditional] = simplified
+                }
+            }
+        }
+    })
+    return t2s
+}
+
+// Width 将全角字母、数字、符号和全角空格转换为对应的半角字符
+func Width(text string) string {
+    return strings.Map(func(ch rune) rune {
+        switch {
+        case ch == '　':
+            return ' '
+        case ch >= '！' && ch <= '～':
+            return ch - 0xFEE0
+        }
+        return ch
+    }, text)
+}
+
+// ToSimplified 将繁体字转换为简体字，对照表中没有的字保持不变
+func ToSimplified(text string) string {
+    table := loadT2S()
+    return strings.Map(func(ch rune) rune {
+        if simplified, ok := table[ch]; ok {
+            return simplified
+        }
+        return ch
+    }, text)
+}
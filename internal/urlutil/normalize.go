@@ -0,0 +1,95 @@
+// Package urlutil 提供 rss 与 storage 共用的URL规范化工具
+package urlutil
+
+import (
+    "net/url"
+    "strings"
+)
+
+// DefaultStripParams 默认去除的追踪参数，以 * 结尾表示前缀匹配
+var DefaultStripParams = []string{
+    "utm_*",
+    "fbclid",
+    "gclid",
+    "dclid",
+    "msclkid",
+    "mc_cid",
+    "mc_eid",
+    "igshid",
+    "spm",
+    "_ga",
+    "ref",
+    "ref_src",
+}
+
+// Normalize 返回URL的规范形式，用于判断不同写法的链接是否指向同一篇文章：
+// scheme 和 host 转为小写，去掉默认端口、片段和路径末尾的斜杠，
+// 去掉默认追踪参数及 extraStripParams 中的参数，剩余参数按名称排序。
+// 无法解析的链接只去除首尾空白后原样返回。
+func Normalize(rawURL string, extraStripParams []string) string {
+    rawURL = strings.TrimSpace(rawURL)
+    u, err := url.Parse(rawURL)
+    if err != nil || u.Host == "" {
+        return rawURL
+    }
+
+    u.Scheme = strings.ToLower(u.Scheme)
+    if u.Scheme == "http" {
+        // 同一文章常同时以 http 和 https 出现，统一为 https
+        u.Scheme = "https"
+    }
+
+    host := strings.ToLower(u.Hostname())
+    port := u.Port()
+    if port != "" && port != "80" && port != "443" {
+        host += ":" + port
+    }
+    u.Host = strings.TrimPrefix(host, "www.")
+
+    u.Fragment = ""
+    u.RawFragment = ""
+    if len(u.Path) > 1 {
+        u.Path = strings.TrimRight(u.Path, "/")
+        u.RawPath = ""
+    }
+    if u.Path == "/" {
+        u.Path = ""
+    }
+
+    query := u.Query()
+    for name := range query {
+        if shouldStrip(name, DefaultStripParams) || shouldStrip(name, extraStripParams) {
+            query.Del(name)
+        }
+    }
+    // url.Values.Encode 会按参数名排序
+    u.RawQuery = query.Encode()
+
+    return u.String()
+}
+
+// shouldStrip 检查参数名是否命中去除规则
+func shouldStrip(name string, rules []string) bool {
+    name = strings.ToLower(name)
+    for _, rule := range rules {
+        rule = strings.ToLower(strings.TrimSpace(rule))
+        if rule == "" {
+            continue
+        }
+        if strings.HasSuffix(rule, "*") {
+            if strings.HasPrefix(name, strings.TrimSuffix(rule, "*")) {
+                return true
+            }
+        } else if name == rule {
+            return true
+        }
+    }
+    return false
+}
+
+// IsURL 检查字符串是否为 http(s) 链接
+func IsURL(s string) bool {
+    s = strings.ToLower(strings.TrimSpace(s))
+    return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
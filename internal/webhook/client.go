@@ -34,13 +34,21 @@ type WebhookClient struct {
 
 // Message webhook 消息结构
 type Message struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Content     string `json:"content"`
-	URL         string `json:"url"`
-	Group       string `json:"group"`
-	Keywords    string `json:"keywords"`
-	Timestamp   string `json:"timestamp"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Content       string   `json:"content"`
+	URL           string   `json:"url"`
+	Group         string   `json:"group"`
+	Keywords      string   `json:"keywords"`
+	Timestamp     string   `json:"timestamp"`
+	Subscription  string   `json:"subscription,omitempty"`
+	FeedTitle     string   `json:"feed_title,omitempty"`
+	Author        string   `json:"author,omitempty"`
+	Categories    []string `json:"categories,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	FuzzyKeywords []string `json:"fuzzy_keywords,omitempty"` // 通过模糊匹配命中的关键词
+	Score         float64  `json:"score"`                    // 命中关键词的权重之和，可用于按相关度排序
 }
 
 // Response webhook 响应结构
@@ -73,6 +81,20 @@ func NewMultiClient(clients []WebhookClient) *MultiClient {
 	}
 }
 
+// Select 返回只包含指定名称 webhook 的客户端，用于按订阅的推送目标发送
+func (mc *MultiClient) Select(names []string) *MultiClient {
+	clients := make([]WebhookClient, 0, len(names))
+	for _, client := range mc.Clients {
+		for _, name := range names {
+			if client.Name == name {
+				clients = append(clients, client)
+				break
+			}
+		}
+	}
+	return NewMultiClient(clients)
+}
+
 // Send 发送消息到 webhook
 func (c *Client) Send(msg Message) error {
 	if !c.Enabled {
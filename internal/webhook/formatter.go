@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"rss2tg/internal/filter"
+	"rss2tg/internal/model"
 )
 
 // Formatter 消息格式转换器
@@ -14,8 +17,13 @@ func NewFormatter() *Formatter {
 	return &Formatter{}
 }
 
+// summaryLength webhook 消息中摘要的最大字符数
+const summaryLength = 300
+
 // FormatMessage 将 rss2tg 消息转换为 webhook 格式
-func (f *Formatter) FormatMessage(title, url, group string, pubDate time.Time, matchedKeywords []string) Message {
+func (f *Formatter) FormatMessage(item *model.Item) Message {
+	title, url, group, pubDate, matchedKeywords := item.Title, item.Link, item.Group, item.Published, item.MatchedKeywords
+
 	// 格式化时间为中国时区
 	chinaLoc, _ := time.LoadLocation("Asia/Shanghai")
 	pubDateChina := pubDate.In(chinaLoc)
@@ -31,6 +39,10 @@ func (f *Formatter) FormatMessage(title, url, group string, pubDate time.Time, m
 	description := fmt.Sprintf("分组: %s", group)
 	if keywords != "" {
 		description += fmt.Sprintf(" | 关键词: %s", keywords)
+		if len(item.FuzzyKeywords) > 0 {
+			description += fmt.Sprintf("（模糊匹配: %s）", strings.Join(item.FuzzyKeywords, ", "))
+		}
+		description += fmt.Sprintf(" | 评分: %s", filter.FormatWeight(item.Score))
 	}
 	description += fmt.Sprintf(" | 时间: %s", timestamp)
 
@@ -46,19 +58,35 @@ func (f *Formatter) FormatMessage(title, url, group string, pubDate time.Time, m
 		keywordTags := make([]string, len(matchedKeywords))
 		for i, keyword := range matchedKeywords {
 			keywordTags[i] = fmt.Sprintf("#%s", keyword)
+			if item.IsFuzzy(keyword) {
+				keywordTags[i] += "(模糊)"
+			}
 		}
 		content += fmt.Sprintf("**关键词：** %s\n\n", strings.Join(keywordTags, " "))
+		content += fmt.Sprintf("**评分：** %s\n\n", filter.FormatWeight(item.Score))
 	}
 	
+	if item.Author != "" {
+		content += fmt.Sprintf("**作者：** %s\n\n", item.Author)
+	}
+
 	content += fmt.Sprintf("**时间：** %s", timestamp)
 
 	return Message{
-		Title:       title,
-		Description: description,
-		Content:     content,
-		URL:         url,
-		Group:       group,
-		Keywords:    keywords,
-		Timestamp:   timestamp,
+		Title:         title,
+		Description:   description,
+		Content:       content,
+		URL:           url,
+		Group:         group,
+		Keywords:      keywords,
+		Timestamp:     timestamp,
+		Subscription:  item.SubscriptionID,
+		FeedTitle:     item.FeedTitle,
+		Author:        item.Author,
+		Categories:    item.Categories,
+		Image:         item.Image,
+		Summary:       item.Summary(summaryLength),
+		FuzzyKeywords: item.FuzzyKeywords,
+		Score:         item.Score,
 	}
 } 
\ No newline at end of file
@@ -8,6 +8,7 @@ import (
     "rss2tg/internal/bot"
     "rss2tg/internal/config"
     "rss2tg/internal/enhancer"
+    "rss2tg/internal/model"
     "rss2tg/internal/rss"
     "rss2tg/internal/storage"
     "rss2tg/internal/stats"
@@ -17,7 +18,6 @@ import (
 type App struct {
     bot        *bot.Bot
     rssManager *rss.Manager
-    config     *config.Config
     db         *storage.Storage
 }
 
@@ -27,23 +27,12 @@ func NewApp(cfg *config.Config, db *storage.Storage, stats *stats.Stats) (*App,
         return nil, err
     }
 
-    rssConfigs := make([]rss.Config, len(cfg.RSS))
-    for i, rssCfg := range cfg.RSS {
-        rssConfigs[i] = rss.Config{
-            URLs:           rssCfg.URLs,
-            Interval:       rssCfg.Interval,
-            Keywords:       rssCfg.Keywords,
-            Group:          rssCfg.Group,
-            AllowPartMatch: rssCfg.AllowPartMatch,
-        }
-    }
-
-    rssManager := rss.NewManager(rssConfigs, db)
+    rssManager := rss.NewManager(buildRSSConfigs(cfg), db)
+    rssManager.SetOptions(buildRSSOptions(cfg))
 
     app := &App{
         bot:        bot,
         rssManager: rssManager,
-        config:     cfg,
         db:         db,
     }
 
@@ -95,29 +84,95 @@ func NewApp(cfg *config.Config, db *storage.Storage, stats *stats.Stats) (*App,
 
     bot.SetMessageHandler(enhancedHandler.HandleMessage)
     bot.SetUpdateRSSHandler(app.updateRSS)
+    bot.SetHealthHandler(rssManager.Health)
+    bot.SetDryRunHandler(rssManager.DryRun)
     rssManager.SetMessageHandler(enhancedHandler.HandleMessage)
+    rssManager.SetAlertHandler(bot.SendAdminAlert)
 
     return app, nil
 }
 
-func (app *App) handleMessage(title, url, group string, pubDate time.Time, matchedKeywords []string) error {
-    return app.bot.SendMessage(title, url, group, pubDate, matchedKeywords)
+func (app *App) handleMessage(item *model.Item) error {
+    return app.bot.SendMessage(item)
 }
 
-func (app *App) updateRSS() {
-    rssConfigs := make([]rss.Config, len(app.config.RSS))
-    for i, rssCfg := range app.config.RSS {
+// updateRSS 按配置更新订阅，由机器人在持有配置写锁时调用
+func (app *App) updateRSS(cfg *config.Config) {
+    app.rssManager.SetOptions(buildRSSOptions(cfg))
+    app.rssManager.UpdateFeeds(buildRSSConfigs(cfg))
+    log.Println("RSS订阅已更新")
+}
+
+// buildRSSConfigs 将配置文件中的订阅转换为 RSS 管理器的配置
+func buildRSSConfigs(cfg *config.Config) []rss.Config {
+    rssConfigs := make([]rss.Config, len(cfg.RSS))
+    for i, rssCfg := range cfg.RSS {
         rssConfigs[i] = rss.Config{
-            URLs:           rssCfg.URLs,
-            Interval:       rssCfg.Interval,
-            Keywords:       rssCfg.Keywords,
-            Group:          rssCfg.Group,
-            AllowPartMatch: rssCfg.AllowPartMatch,
-            Enabled:        rssCfg.Enabled,
+            ID:                rssCfg.ID,
+            URLs:              rssCfg.URLs,
+            Interval:          rssCfg.Interval,
+            Keywords:          rssCfg.Keywords,
+            Group:             rssCfg.Group,
+            AllowPartMatch:    rssCfg.AllowPartMatch,
+            Enabled:           rssCfg.Enabled,
+            PrimeOnFirstFetch: rssCfg.PrimeOnFirstFetch,
+            PrimeSendNewest:   rssCfg.PrimeSendNewest,
+            DedupKey:          rssCfg.DedupKey,
+            StripParams:       rssCfg.StripParams,
+            MaxAgeHours:       rssCfg.MaxAgeHours,
+            ExcludeKeywords:   rssCfg.ExcludeKeywords,
+            Regex:             buildRegexRules(rssCfg.Regex),
+            MatchFields:       rssCfg.MatchFields,
+            KeepPunctuation:   !rssCfg.StripsPunctuation(),
+            FuzzyMatch:        rssCfg.FuzzyMatch,
+            MinScore:          rssCfg.MinScore,
+            KeywordSets:       rssCfg.KeywordSets,
+            Route:             buildRoute(cfg.RouteFor(&cfg.RSS[i])),
         }
     }
-    app.rssManager.UpdateFeeds(rssConfigs)
-    log.Println("RSS订阅已更新")
+    return rssConfigs
+}
+
+// buildRoute 将配置文件中的推送目标转换为文章的推送目标
+func buildRoute(route config.Route) model.Route {
+    return model.Route{
+        Users:    route.Users,
+        Channels: route.Channels,
+        Webhooks: route.Webhooks,
+    }
+}
+
+// buildRegexRules 将配置文件中的正则规则转换为 RSS 管理器的规则
+func buildRegexRules(rules []config.RegexRule) []rss.RegexRule {
+    if len(rules) == 0 {
+        return nil
+    }
+    regexRules := make([]rss.RegexRule, len(rules))
+    for i, rule := range rules {
+        regexRules[i] = rss.RegexRule{Name: rule.Name, Pattern: rule.Pattern, Weight: rule.Weight}
+    }
+    return regexRules
+}
+
+// buildRSSOptions 将配置文件中的全局设置转换为 RSS 管理器的选项
+func buildRSSOptions(cfg *config.Config) rss.Options {
+    return rss.Options{
+        AlertAfter:          time.Duration(cfg.Health.AlertAfterHours) * time.Hour,
+        MaxBackoff:          time.Duration(cfg.Health.MaxBackoff) * time.Second,
+        CrossFeedDedup:      cfg.Dedup.CrossFeed,
+        DuplicateWindow:     time.Duration(cfg.Dedup.WindowHours) * time.Hour,
+        DuplicateSimilarity: cfg.Dedup.Similarity,
+        MaxConcurrency:      cfg.Scheduler.MaxConcurrency,
+        PerHostConcurrency:  cfg.Scheduler.PerHostConcurrency,
+        StartJitter:         time.Duration(cfg.Scheduler.StartJitter) * time.Second,
+        Blocklist:           cfg.Blocklist,
+        KeywordSets:         cfg.KeywordSets,
+        Normalize: rss.NormalizeOptions{
+            Synonyms:                cfg.NormalizeSynonyms(),
+            FoldWidth:               cfg.Normalize.FoldWidth,
+            TraditionalToSimplified: cfg.Normalize.TraditionalToSimplified,
+        },
+    }
 }
 
 func (app *App) Start() {
@@ -133,17 +188,14 @@ func (app *App) watchConfig() {
     for {
         select {
         case <-ticker.C:
-            newCfg, err := config.Load("/app/config/config.yaml")
+            // 配置由机器人持有，加载、比较和替换都在机器人的配置锁内进行
+            changed, err := app.bot.UpdateConfig()
             if err != nil {
                 log.Printf("加载配置失败: %v", err)
                 continue
             }
-
-            if !app.config.Equal(newCfg) {
-                log.Println("检测到配置变更，正在更新...")
-                app.config = newCfg
-                app.bot.UpdateConfig(newCfg)
-                app.updateRSS()
+            if changed {
+                log.Println("检测到配置变更，已更新")
             }
         }
     }